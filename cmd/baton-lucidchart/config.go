@@ -1,6 +1,14 @@
 package main
 
 import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector"
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
 	"github.com/conductorone/baton-sdk/pkg/field"
 	"github.com/spf13/viper"
 )
@@ -8,36 +16,159 @@ import (
 var (
 	LucidApiKeyField = field.StringField(
 		"lucid-api-key",
-		field.WithDescription("The API key for the Lucidchart API."),
-		field.WithRequired(true),
+		field.WithDescription("The API key for the Lucidchart API. Without an API key, every request is authorized with OAuth2, whose account-scoped token then needs the scopes of the folders, documents and shares."),
 	)
 
 	LucidCodeKeyField = field.StringField(
 		"lucid-code",
-		field.WithDescription("The code key for the Lucidchart API."),
+		field.WithDescription("The code key for the Lucidchart API. Either a code or a refresh token is required with an OAuth2 client."),
 	)
 
 	LucidClientIdField = field.StringField(
 		"lucid-client-id",
-		field.WithDescription("The client ID for the Lucidchart API."),
-		field.WithRequired(true),
+		field.WithDescription("The client ID for the Lucidchart API. Without an OAuth2 client, the connector runs with the API key only, and teams and events are disabled."),
 	)
 
 	LucidClientSecretField = field.StringField(
 		"lucid-client-secret",
 		field.WithDescription("The client secret for the Lucidchart API."),
-		field.WithRequired(true),
 	)
 
 	LucidRedirectUrlField = field.StringField(
 		"lucid-redirect-url",
-		field.WithDescription("The redirect URL for the Lucidchart API."),
-		field.WithRequired(true),
+		field.WithDescription("The redirect URL for the Lucidchart API, the one the code was generated for. Required with a code."),
 	)
 
 	LucidRefreshTokenField = field.StringField(
 		"lucid-refresh-token",
-		field.WithDescription("The refresh token for the Lucidchart API."),
+		field.WithDescription("The refresh token for the Lucidchart API. Either a code or a refresh token is required with an OAuth2 client."),
+	)
+
+	LucidRegionField = field.StringField(
+		"lucid-region",
+		field.WithDescription("The region of the Lucid account: us, or fedramp for FedRAMP accounts. It selects the URL of the Lucid API, us when unset."),
+	)
+
+	LucidBaseUrlField = field.StringField(
+		"lucid-base-url",
+		field.WithDescription("The URL of the Lucid API, e.g. a proxy. It must be the API of the region when both are set."),
+	)
+
+	LucidPageSizeField = field.IntField(
+		"lucid-page-size",
+		field.WithDescription("The number of items requested in each page from Lucid, so that large accounts sync in fewer requests. Lucid's default page size is used when 0, and the maximum is 200."),
+	)
+
+	LucidPrefetchConcurrencyField = field.IntField(
+		"lucid-prefetch-concurrency",
+		field.WithDescription("The number of folders listed at the same time ahead of the sync, to sync deep folder trees faster. No prefetch when 0."),
+	)
+
+	LucidCollaboratorConcurrencyField = field.IntField(
+		"lucid-collaborator-concurrency",
+		field.WithDescription("The number of documents whose collaborators are loaded at the same time ahead of their grants, to sync folders with many documents faster. No loading ahead when 0."),
+	)
+
+	LucidRequestsPerSecondField = field.IntField(
+		"lucid-requests-per-second",
+		field.WithDescription("The maximum number of requests per second sent to Lucid, shared by the sync, the prefetch of the folder tree, the loading of collaborators and offboarding. No limit when 0."),
+	)
+
+	LucidAuthTypesField = field.StringSliceField(
+		"lucid-auth-types",
+		field.WithDescription("The credential authorizing the requests of Lucid endpoints, as endpoint=auth-type, e.g. shares=oauth2. The endpoints are users, teams, audit-logs, folders, documents and shares, and the auth types api-key and oauth2."),
+	)
+
+	LucidRecordCassetteField = field.StringField(
+		"lucid-record-cassette",
+		field.WithDescription("The path of a cassette file to record the requests to Lucid into, with the secrets and personal details redacted."),
+	)
+
+	LucidReplayCassetteField = field.StringField(
+		"lucid-replay-cassette",
+		field.WithDescription("The path of a cassette file to replay the requests to Lucid from, without any network access."),
+	)
+
+	LucidShareLinkMaxExpirationField = field.StringField(
+		"lucid-share-link-max-expiration",
+		field.WithDescription("The longest duration a share link can be valid for, e.g. 720h. Share links must expire when set."),
+	)
+
+	LucidShareLinkDenyAnonymousField = field.BoolField(
+		"lucid-share-link-deny-anonymous",
+		field.WithDescription("Reject share links that allow anonymous access."),
+	)
+
+	LucidShareLinkRequirePasscodeField = field.BoolField(
+		"lucid-share-link-require-passcode",
+		field.WithDescription("Reject share links without a passcode."),
+	)
+
+	LucidShareLinkRequireAccountRestrictionField = field.BoolField(
+		"lucid-share-link-require-account-restriction",
+		field.WithDescription("Reject share links that are not restricted to the Lucid account."),
+	)
+
+	LucidShareLinkAllowedRolesField = field.StringSliceField(
+		"lucid-share-link-allowed-roles",
+		field.WithDescription("The roles share links can be created with. All roles are allowed when empty."),
+	)
+
+	LucidIncludeFolderIdsField = field.StringSliceField(
+		"lucid-include-folder-ids",
+		field.WithDescription("The IDs of the folders to sync, with their subfolders and documents. All folders are synced when no folder is included."),
+	)
+
+	LucidIncludeFolderNamesField = field.StringSliceField(
+		"lucid-include-folder-names",
+		field.WithDescription("The name patterns, e.g. 'Engineering*', of the folders to sync, with their subfolders and documents."),
+	)
+
+	LucidExcludeFolderIdsField = field.StringSliceField(
+		"lucid-exclude-folder-ids",
+		field.WithDescription("The IDs of the folders to skip, with their subfolders and documents."),
+	)
+
+	LucidExcludeFolderNamesField = field.StringSliceField(
+		"lucid-exclude-folder-names",
+		field.WithDescription("The name patterns, e.g. 'Archive*', of the folders to skip, with their subfolders and documents."),
+	)
+
+	LucidMaxFolderDepthField = field.IntField(
+		"lucid-max-folder-depth",
+		field.WithDescription("The deepest level of folders to sync, the folders at the root being at depth 1. No limit when 0."),
+	)
+
+	LucidSyncDocumentsField = field.StringField(
+		"lucid-sync-documents",
+		field.WithDescription("Which documents to sync: all, none to only sync folders, or direct-shares to only sync the documents shared differently than their folder, which still lists the collaborators of every document."),
+		field.WithDefaultValue(string(connector.DocumentSyncAll)),
+	)
+
+	LucidTrashedContentField = field.StringField(
+		"lucid-trashed-content",
+		field.WithDescription("How to sync trashed folders and documents: include, exclude, or mark them with when they were trashed in their description."),
+		field.WithDefaultValue(string(connector.TrashedContentInclude)),
+	)
+
+	LucidPermanentDeleteField = field.BoolField(
+		"lucid-permanent-delete",
+		field.WithDescription("Delete folders and documents permanently, instead of moving them to the trash. Trashed content is restored by creating it with its id and the restore setting."),
+	)
+
+	LucidLucidchartTemplateIdField = field.StringField(
+		"lucid-lucidchart-template-id",
+		field.WithDescription("The id of the document copied to create Lucidchart documents. Blank documents are created when unset."),
+	)
+
+	LucidLucidsparkTemplateIdField = field.StringField(
+		"lucid-lucidspark-template-id",
+		field.WithDescription("The id of the board copied to create Lucidspark boards. Blank boards are created when unset."),
+	)
+
+	LucidLucidscaleTemplateIdField = field.StringField(
+		"lucid-lucidscale-template-id",
+		field.WithDescription("The id of the model copied to create Lucidscale models, which can't be created blank."),
 	)
 
 	// ConfigurationFields defines the external configuration required for the
@@ -50,19 +181,179 @@ var (
 		LucidClientSecretField,
 		LucidRedirectUrlField,
 		LucidRefreshTokenField,
+		LucidRegionField,
+		LucidBaseUrlField,
+		LucidAuthTypesField,
+		LucidPageSizeField,
+		LucidPrefetchConcurrencyField,
+		LucidCollaboratorConcurrencyField,
+		LucidRequestsPerSecondField,
+		LucidRecordCassetteField,
+		LucidReplayCassetteField,
+		LucidShareLinkMaxExpirationField,
+		LucidShareLinkDenyAnonymousField,
+		LucidShareLinkRequirePasscodeField,
+		LucidShareLinkRequireAccountRestrictionField,
+		LucidShareLinkAllowedRolesField,
+		LucidIncludeFolderIdsField,
+		LucidIncludeFolderNamesField,
+		LucidExcludeFolderIdsField,
+		LucidExcludeFolderNamesField,
+		LucidMaxFolderDepthField,
+		LucidSyncDocumentsField,
+		LucidTrashedContentField,
+		LucidPermanentDeleteField,
+		LucidLucidchartTemplateIdField,
+		LucidLucidsparkTemplateIdField,
+		LucidLucidscaleTemplateIdField,
 	}
 
 	// FieldRelationships defines relationships between the fields listed in
 	// ConfigurationFields that can be automatically validated. For example, a
 	// username and password can be required together, or an access token can be
 	// marked as mutually exclusive from the username password pair.
-	FieldRelationships = []field.SchemaFieldRelationship{}
+	FieldRelationships = []field.SchemaFieldRelationship{
+		field.FieldsAtLeastOneUsed(LucidApiKeyField, LucidClientIdField),
+		field.FieldsRequiredTogether(LucidClientIdField, LucidClientSecretField),
+		field.FieldsDependentOn([]field.SchemaField{LucidCodeKeyField}, []field.SchemaField{LucidClientIdField, LucidRedirectUrlField}),
+		field.FieldsDependentOn([]field.SchemaField{LucidRefreshTokenField}, []field.SchemaField{LucidClientIdField}),
+		field.FieldsMutuallyExclusive(LucidRecordCassetteField, LucidReplayCassetteField),
+	}
+
+	// lucidRegionUrls are the URLs of the Lucid API of each region.
+	lucidRegionUrls = map[string]client.ClientUrl{
+		"us":      client.LucidchartApiUrl,
+		"fedramp": client.LucidchartApiFedRampUrl,
+	}
 )
 
+// lucidBaseUrl returns the URL of the Lucid API, the base URL when set and the API of the region otherwise.
+func lucidBaseUrl(v *viper.Viper) string {
+	if baseUrl := v.GetString(LucidBaseUrlField.FieldName); baseUrl != "" {
+		return baseUrl
+	}
+
+	return string(lucidRegionUrls[v.GetString(LucidRegionField.FieldName)])
+}
+
+// shareLinkMaxExpiration returns the longest duration a share link can be valid for, 0 when unlimited.
+func shareLinkMaxExpiration(v *viper.Viper) (time.Duration, error) {
+	maxExpiration := v.GetString(LucidShareLinkMaxExpirationField.FieldName)
+	if maxExpiration == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(maxExpiration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", LucidShareLinkMaxExpirationField.FieldName, err)
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("%s must be positive", LucidShareLinkMaxExpirationField.FieldName)
+	}
+
+	return duration, nil
+}
+
+// validateUrl returns an error unless the value of the field is an absolute http or https URL.
+func validateUrl(f field.SchemaField, value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid %s: %s", f.FieldName, value)
+	}
+
+	return nil
+}
+
 // ValidateConfig is run after the configuration is loaded, and should return an
 // error if it isn't valid. Implementing this function is optional, it only
 // needs to perform extra validations that cannot be encoded with configuration
 // parameters.
 func ValidateConfig(v *viper.Viper) error {
+	// An OAuth2 client needs a code or a refresh token, the connector runs with the API key only without one.
+	if v.GetString(LucidClientIdField.FieldName) != "" &&
+		v.GetString(LucidCodeKeyField.FieldName) == "" && v.GetString(LucidRefreshTokenField.FieldName) == "" {
+		return fmt.Errorf("%s needs either %s or %s", LucidClientIdField.FieldName, LucidCodeKeyField.FieldName, LucidRefreshTokenField.FieldName)
+	}
+
+	if _, err := shareLinkMaxExpiration(v); err != nil {
+		return err
+	}
+
+	for _, f := range []field.SchemaField{LucidIncludeFolderNamesField, LucidExcludeFolderNamesField} {
+		for _, pattern := range v.GetStringSlice(f.FieldName) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid %s pattern %s: %w", f.FieldName, pattern, err)
+			}
+		}
+	}
+
+	switch connector.DocumentSyncMode(v.GetString(LucidSyncDocumentsField.FieldName)) {
+	case "", connector.DocumentSyncAll, connector.DocumentSyncNone, connector.DocumentSyncDirectShares:
+	default:
+		return fmt.Errorf("invalid %s: %s", LucidSyncDocumentsField.FieldName, v.GetString(LucidSyncDocumentsField.FieldName))
+	}
+
+	switch connector.TrashedContentPolicy(v.GetString(LucidTrashedContentField.FieldName)) {
+	case "", connector.TrashedContentInclude, connector.TrashedContentExclude, connector.TrashedContentMark:
+	default:
+		return fmt.Errorf("invalid %s: %s", LucidTrashedContentField.FieldName, v.GetString(LucidTrashedContentField.FieldName))
+	}
+
+	authTypes, err := client.ParseAuthTypes(v.GetStringSlice(LucidAuthTypesField.FieldName))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", LucidAuthTypesField.FieldName, err)
+	}
+
+	for endpoint, authType := range authTypes {
+		if authType == client.LucidAuthTypeApiKey && v.GetString(LucidApiKeyField.FieldName) == "" {
+			return fmt.Errorf("%s authorizes %s with an API key, but %s isn't set", LucidAuthTypesField.FieldName, endpoint, LucidApiKeyField.FieldName)
+		}
+
+		if authType == client.LucidAuthTypeOAuth2 && v.GetString(LucidClientIdField.FieldName) == "" {
+			return fmt.Errorf("%s authorizes %s with OAuth2, but %s isn't set", LucidAuthTypesField.FieldName, endpoint, LucidClientIdField.FieldName)
+		}
+	}
+
+	region := v.GetString(LucidRegionField.FieldName)
+	regionUrl, ok := lucidRegionUrls[region]
+	if region != "" && !ok {
+		return fmt.Errorf("invalid %s: %s", LucidRegionField.FieldName, region)
+	}
+
+	if baseUrl := v.GetString(LucidBaseUrlField.FieldName); baseUrl != "" {
+		if err := validateUrl(LucidBaseUrlField, baseUrl); err != nil {
+			return err
+		}
+
+		if region != "" && strings.TrimSuffix(baseUrl, "/") != string(regionUrl) {
+			return fmt.Errorf("%s %s isn't the API of the %s region, %s", LucidBaseUrlField.FieldName, baseUrl, region, regionUrl)
+		}
+	}
+
+	if redirectUrl := v.GetString(LucidRedirectUrlField.FieldName); redirectUrl != "" {
+		if err := validateUrl(LucidRedirectUrlField, redirectUrl); err != nil {
+			return err
+		}
+	}
+
+	if v.GetInt(LucidPageSizeField.FieldName) < 0 {
+		return fmt.Errorf("%s can't be negative", LucidPageSizeField.FieldName)
+	}
+
+	for _, f := range []field.SchemaField{
+		LucidPrefetchConcurrencyField,
+		LucidCollaboratorConcurrencyField,
+		LucidRequestsPerSecondField,
+	} {
+		if v.GetInt(f.FieldName) < 0 {
+			return fmt.Errorf("%s can't be negative", f.FieldName)
+		}
+	}
+
+	if v.GetInt(LucidMaxFolderDepthField.FieldName) < 0 {
+		return fmt.Errorf("%s can't be negative", LucidMaxFolderDepthField.FieldName)
+	}
+
 	return nil
 }
@@ -5,8 +5,31 @@ import (
 
 	"github.com/conductorone/baton-sdk/pkg/field"
 	"github.com/conductorone/baton-sdk/pkg/test"
+	"github.com/stretchr/testify/require"
 )
 
+// withConfigs returns the configuration of a sync with a refresh token, with the configs added and the
+// configs set to an empty value removed.
+func withConfigs(configs map[string]string) map[string]string {
+	rv := map[string]string{
+		"lucid-api-key":       "api-key",
+		"lucid-client-id":     "client-id",
+		"lucid-client-secret": "client-secret",
+		"lucid-refresh-token": "refresh-token",
+	}
+
+	for key, value := range configs {
+		if value == "" {
+			delete(rv, key)
+			continue
+		}
+
+		rv[key] = value
+	}
+
+	return rv
+}
+
 func TestConfigs(t *testing.T) {
 	configurationSchema := field.NewConfiguration(
 		ConfigurationFields,
@@ -14,8 +37,205 @@ func TestConfigs(t *testing.T) {
 	)
 
 	testCases := []test.TestCase{
-		// Add test cases here.
+		{
+			Configs: withConfigs(nil),
+			IsValid: true,
+			Message: "refresh token",
+		},
+		{
+			Configs: withConfigs(map[string]string{
+				"lucid-refresh-token": "",
+				"lucid-code":          "code",
+				"lucid-redirect-url":  "https://example.com/callback",
+			}),
+			IsValid: true,
+			Message: "code with a redirect URL",
+		},
+		{
+			Configs: withConfigs(map[string]string{
+				"lucid-code":         "code",
+				"lucid-redirect-url": "https://example.com/callback",
+			}),
+			IsValid: true,
+			Message: "code and refresh token",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-refresh-token": ""}),
+			IsValid: false,
+			Message: "client without code nor refresh token",
+		},
+		{
+			Configs: map[string]string{"lucid-api-key": "api-key"},
+			IsValid: true,
+			Message: "API key only",
+		},
+		{
+			Configs: map[string]string{"lucid-api-key": "api-key", "lucid-refresh-token": "refresh-token"},
+			IsValid: false,
+			Message: "refresh token without a client",
+		},
+		{
+			Configs: map[string]string{"lucid-api-key": "api-key", "lucid-code": "code", "lucid-redirect-url": "https://example.com/callback"},
+			IsValid: false,
+			Message: "code without a client",
+		},
+		{
+			Configs: withConfigs(map[string]string{
+				"lucid-refresh-token": "",
+				"lucid-code":          "code",
+			}),
+			IsValid: false,
+			Message: "code without a redirect URL",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-redirect-url": "callback"}),
+			IsValid: false,
+			Message: "relative redirect URL",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-api-key": ""}),
+			IsValid: true,
+			Message: "OAuth2 only",
+		},
+		{
+			Configs: map[string]string{},
+			IsValid: false,
+			Message: "no credentials",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-auth-types": "shares=oauth2"}),
+			IsValid: true,
+			Message: "auth type of an endpoint",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-auth-types": "comments=oauth2"}),
+			IsValid: false,
+			Message: "auth type of an unknown endpoint",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-auth-types": "shares=basic"}),
+			IsValid: false,
+			Message: "unknown auth type",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-api-key": "", "lucid-auth-types": "folders=api-key"}),
+			IsValid: false,
+			Message: "API key auth type without an API key",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-auth-types": "teams=api-key"}),
+			IsValid: false,
+			Message: "API key auth type of the teams",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-auth-types": "audit-logs=api-key"}),
+			IsValid: false,
+			Message: "API key auth type of the audit logs",
+		},
+		{
+			Configs: map[string]string{"lucid-api-key": "api-key", "lucid-auth-types": "users=oauth2"},
+			IsValid: false,
+			Message: "OAuth2 auth type without a client",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-client-secret": ""}),
+			IsValid: false,
+			Message: "client without a secret",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-region": "fedramp"}),
+			IsValid: true,
+			Message: "region",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-region": "mars"}),
+			IsValid: false,
+			Message: "unknown region",
+		},
+		{
+			Configs: withConfigs(map[string]string{
+				"lucid-region":   "fedramp",
+				"lucid-base-url": "https://api.lucidgov.app/",
+			}),
+			IsValid: true,
+			Message: "base URL of the region",
+		},
+		{
+			Configs: withConfigs(map[string]string{
+				"lucid-region":   "fedramp",
+				"lucid-base-url": "https://api.lucid.co",
+			}),
+			IsValid: false,
+			Message: "base URL of another region",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-base-url": "http://localhost:8080"}),
+			IsValid: true,
+			Message: "base URL",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-base-url": "ftp://api.lucid.co"}),
+			IsValid: false,
+			Message: "base URL without http",
+		},
+		{
+			Configs: withConfigs(map[string]string{
+				"lucid-record-cassette": "cassette.jsonl",
+				"lucid-replay-cassette": "cassette.jsonl",
+			}),
+			IsValid: false,
+			Message: "record and replay cassettes",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-sync-documents": "some"}),
+			IsValid: false,
+			Message: "unknown document sync mode",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-share-link-max-expiration": "-1h"}),
+			IsValid: false,
+			Message: "negative share link expiration",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-page-size": "-1"}),
+			IsValid: false,
+			Message: "negative page size",
+		},
+		{
+			Configs: withConfigs(map[string]string{"lucid-page-size": "200"}),
+			IsValid: true,
+			Message: "maximum page size",
+		},
 	}
 
 	test.ExerciseTestCases(t, configurationSchema, ValidateConfig, testCases)
 }
+
+func TestLucidBaseUrl(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs map[string]string
+		want    string
+	}{
+		{
+			name: "default",
+			want: "",
+		},
+		{
+			name:    "region",
+			configs: map[string]string{"lucid-region": "fedramp"},
+			want:    "https://api.lucidgov.app",
+		},
+		{
+			name:    "base URL",
+			configs: map[string]string{"lucid-region": "fedramp", "lucid-base-url": "https://api.lucidgov.app/"},
+			want:    "https://api.lucidgov.app/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, lucidBaseUrl(test.MakeViper(tt.configs)))
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/conductorone/baton-lucidchart/pkg/connector"
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
 	"github.com/conductorone/baton-sdk/pkg/config"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/field"
@@ -20,13 +21,11 @@ var version = "dev"
 func main() {
 	ctx := context.Background()
 
-	_, cmd, err := config.DefineConfiguration(
+	v, cmd, err := config.DefineConfiguration(
 		ctx,
 		"baton-lucidchart",
 		getConnector,
-		field.Configuration{
-			Fields: ConfigurationFields,
-		},
+		field.NewConfiguration(ConfigurationFields, FieldRelationships...),
 	)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
@@ -34,6 +33,7 @@ func main() {
 	}
 
 	cmd.Version = version
+	cmd.AddCommand(newOffboardCommand(ctx, cmd, v))
 
 	err = cmd.Execute()
 	if err != nil {
@@ -44,6 +44,27 @@ func main() {
 
 func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, error) {
 	l := ctxzap.Extract(ctx)
+
+	cb, err := newLucidConnector(ctx, v)
+	if err != nil {
+		l.Error("error creating connector", zap.Error(err))
+		return nil, err
+	}
+	connector, err := connectorbuilder.NewConnector(ctx, cb)
+	if err != nil {
+		l.Error("error creating connector", zap.Error(err))
+		return nil, err
+	}
+	return connector, nil
+}
+
+// newLucidConnector validates the configuration and builds the Lucid connector from it.
+func newLucidConnector(ctx context.Context, v *viper.Viper) (*connector.Connector, error) {
+	// The relationships are checked again for the subcommands, which the SDK doesn't validate.
+	if err := field.Validate(field.NewConfiguration(ConfigurationFields, FieldRelationships...), v); err != nil {
+		return nil, err
+	}
+
 	if err := ValidateConfig(v); err != nil {
 		return nil, err
 	}
@@ -55,15 +76,69 @@ func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, e
 	redirectURL := v.GetString(LucidRedirectUrlField.FieldName)
 	refreshToken := v.GetString(LucidRefreshTokenField.FieldName)
 
-	cb, err := connector.New(ctx, apiKey, code, clientID, clientSecret, redirectURL, refreshToken)
+	maxExpiration, err := shareLinkMaxExpiration(v)
 	if err != nil {
-		l.Error("error creating connector", zap.Error(err))
 		return nil, err
 	}
-	connector, err := connectorbuilder.NewConnector(ctx, cb)
+
+	shareLinkPolicy := connector.ShareLinkPolicy{
+		MaxExpiration:             maxExpiration,
+		DenyAnonymous:             v.GetBool(LucidShareLinkDenyAnonymousField.FieldName),
+		RequirePasscode:           v.GetBool(LucidShareLinkRequirePasscodeField.FieldName),
+		RequireAccountRestriction: v.GetBool(LucidShareLinkRequireAccountRestrictionField.FieldName),
+		AllowedRoles:              v.GetStringSlice(LucidShareLinkAllowedRolesField.FieldName),
+	}
+
+	folderScope := connector.FolderScope{
+		IncludeIds:   v.GetStringSlice(LucidIncludeFolderIdsField.FieldName),
+		IncludeNames: v.GetStringSlice(LucidIncludeFolderNamesField.FieldName),
+		ExcludeIds:   v.GetStringSlice(LucidExcludeFolderIdsField.FieldName),
+		ExcludeNames: v.GetStringSlice(LucidExcludeFolderNamesField.FieldName),
+		MaxDepth:     v.GetInt(LucidMaxFolderDepthField.FieldName),
+	}
+
+	documentTemplates := map[string]string{
+		client.ProductLucidchart: v.GetString(LucidLucidchartTemplateIdField.FieldName),
+		client.ProductLucidspark: v.GetString(LucidLucidsparkTemplateIdField.FieldName),
+		client.ProductLucidscale: v.GetString(LucidLucidscaleTemplateIdField.FieldName),
+	}
+
+	authTypes, err := client.ParseAuthTypes(v.GetStringSlice(LucidAuthTypesField.FieldName))
 	if err != nil {
-		l.Error("error creating connector", zap.Error(err))
 		return nil, err
 	}
-	return connector, nil
+
+	opts := []connector.Option{
+		connector.WithShareLinkPolicy(shareLinkPolicy),
+		connector.WithFolderScope(folderScope),
+		connector.WithDocumentSyncMode(connector.DocumentSyncMode(v.GetString(LucidSyncDocumentsField.FieldName))),
+		connector.WithTrashedContentPolicy(connector.TrashedContentPolicy(v.GetString(LucidTrashedContentField.FieldName))),
+		connector.WithPermanentDelete(v.GetBool(LucidPermanentDeleteField.FieldName)),
+		connector.WithDocumentTemplates(documentTemplates),
+		connector.WithBaseUrl(lucidBaseUrl(v)),
+		connector.WithAuthTypes(authTypes),
+		connector.WithPageSize(v.GetInt(LucidPageSizeField.FieldName)),
+		connector.WithPrefetch(v.GetInt(LucidPrefetchConcurrencyField.FieldName)),
+		connector.WithCollaboratorLoader(v.GetInt(LucidCollaboratorConcurrencyField.FieldName)),
+		connector.WithRequestsPerSecond(v.GetInt(LucidRequestsPerSecondField.FieldName)),
+	}
+
+	if path := v.GetString(LucidRecordCassetteField.FieldName); path != "" {
+		opts = append(opts, connector.WithCassette(client.CassetteRecord, path))
+	}
+
+	if path := v.GetString(LucidReplayCassetteField.FieldName); path != "" {
+		opts = append(opts, connector.WithCassette(client.CassetteReplay, path))
+	}
+
+	return connector.New(
+		ctx,
+		apiKey,
+		code,
+		clientID,
+		clientSecret,
+		redirectURL,
+		refreshToken,
+		opts...,
+	)
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// newOffboardCommand returns the offboard command, which removes a user from every folder and document
+// share and prints a JSON report of each removal. It takes the same configuration as the main command, whose
+// --lucid-requests-per-second limits the requests of the sweep.
+func newOffboardCommand(ctx context.Context, mainCmd *cobra.Command, v *viper.Viper) *cobra.Command {
+	var (
+		userId      string
+		dryRun      bool
+		concurrency int
+	)
+
+	cmd := &cobra.Command{
+		Use:           "offboard",
+		Short:         "Remove a user from every folder and document share",
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			err := v.BindPFlags(cmd.Flags())
+			if err != nil {
+				return err
+			}
+
+			c, err := newLucidConnector(ctx, v)
+			if err != nil {
+				return err
+			}
+
+			report, err := c.Offboard(ctx, userId, connector.OffboardOptions{
+				DryRun:      dryRun,
+				Concurrency: concurrency,
+			})
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+
+			err = encoder.Encode(report)
+			if err != nil {
+				return err
+			}
+
+			if failed := report.Failed(); failed > 0 {
+				return fmt.Errorf("%d of %d shares couldn't be removed", failed, len(report.Items))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().AddFlagSet(mainCmd.Flags())
+	cmd.Flags().StringVar(&userId, "user-id", "", "The id of the user to remove from every share.")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only list the shares that would be removed.")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 4, "The number of shares removed at the same time.")
+	_ = cmd.MarkFlagRequired("user-id")
+
+	return cmd
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LucidEndpoint is a group of Lucid endpoints whose requests are authorized the same way.
+type LucidEndpoint string
+
+const (
+	LucidEndpointUsers     LucidEndpoint = "users"
+	LucidEndpointTeams     LucidEndpoint = "teams"
+	LucidEndpointAuditLogs LucidEndpoint = "audit-logs"
+	LucidEndpointFolders   LucidEndpoint = "folders"
+	LucidEndpointDocuments LucidEndpoint = "documents"
+	// LucidEndpointShares are the collaborators and share links of the folders and documents.
+	LucidEndpointShares LucidEndpoint = "shares"
+)
+
+// LucidEndpoints are the endpoints whose auth type can be configured.
+var LucidEndpoints = []LucidEndpoint{
+	LucidEndpointUsers,
+	LucidEndpointTeams,
+	LucidEndpointAuditLogs,
+	LucidEndpointFolders,
+	LucidEndpointDocuments,
+	LucidEndpointShares,
+}
+
+// oauth2OnlyEndpoints are the endpoints Lucid only serves to OAuth2 tokens, which can't be authorized with an
+// API key.
+var oauth2OnlyEndpoints = map[LucidEndpoint]bool{
+	LucidEndpointTeams:     true,
+	LucidEndpointAuditLogs: true,
+}
+
+// authTypeNames are the names of the auth types in the configuration.
+var authTypeNames = map[string]LucidAuthType{
+	"api-key": LucidAuthTypeApiKey,
+	"oauth2":  LucidAuthTypeOAuth2,
+}
+
+// WithAuthTypes authorizes the requests of the endpoints with another credential than their default one, e.g.
+// OAuth2 for the folders of an account preferring an account-level OAuth2 app over API keys.
+func WithAuthTypes(authTypes map[LucidEndpoint]LucidAuthType) ClientOption {
+	return func(c *LucidchartClient) {
+		c.authTypes = authTypes
+	}
+}
+
+// ParseAuthTypes parses the auth types of endpoints written as endpoint=auth-type, e.g. shares=oauth2, the
+// auth type being either api-key or oauth2.
+func ParseAuthTypes(values []string) (map[LucidEndpoint]LucidAuthType, error) {
+	authTypes := make(map[LucidEndpoint]LucidAuthType, len(values))
+
+	for _, value := range values {
+		name, authTypeName, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("baton-lucidchart: invalid auth type %s, expected endpoint=auth-type", value)
+		}
+
+		endpoint := LucidEndpoint(strings.TrimSpace(name))
+		if !isLucidEndpoint(endpoint) {
+			return nil, fmt.Errorf("baton-lucidchart: unknown endpoint %s, expected one of %s", endpoint, joinEndpoints())
+		}
+
+		authType, ok := authTypeNames[strings.ToLower(strings.TrimSpace(authTypeName))]
+		if !ok {
+			return nil, fmt.Errorf("baton-lucidchart: unknown auth type %s of %s, expected api-key or oauth2", authTypeName, endpoint)
+		}
+
+		if err := ValidateAuthType(endpoint, authType); err != nil {
+			return nil, err
+		}
+
+		authTypes[endpoint] = authType
+	}
+
+	return authTypes, nil
+}
+
+// ValidateAuthType returns an error when the endpoint can't be authorized with the auth type.
+func ValidateAuthType(endpoint LucidEndpoint, authType LucidAuthType) error {
+	if authType == LucidAuthTypeApiKey && oauth2OnlyEndpoints[endpoint] {
+		return fmt.Errorf("baton-lucidchart: the %s endpoint only accepts OAuth2 tokens, not an API key", endpoint)
+	}
+
+	return nil
+}
+
+func isLucidEndpoint(endpoint LucidEndpoint) bool {
+	for _, e := range LucidEndpoints {
+		if e == endpoint {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinEndpoints() string {
+	names := make([]string, 0, len(LucidEndpoints))
+	for _, e := range LucidEndpoints {
+		names = append(names, string(e))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// endpointOfPath returns the endpoint of a request path, empty for the paths outside of the known endpoints.
+func endpointOfPath(path string) LucidEndpoint {
+	if strings.Contains(path, "/shares/") || strings.HasSuffix(path, "/shares") {
+		return LucidEndpointShares
+	}
+
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+
+	switch segment {
+	case "users":
+		return LucidEndpointUsers
+	case "teams":
+		return LucidEndpointTeams
+	case "auditLogs":
+		return LucidEndpointAuditLogs
+	case "folders":
+		return LucidEndpointFolders
+	case "documents":
+		return LucidEndpointDocuments
+	}
+
+	return ""
+}
+
+// authType returns the auth type of the requests to a path: the one configured for its endpoint, or the
+// default one of the request. API key requests are authorized with OAuth2 when the client has no API key.
+func (c *LucidchartClient) authType(path string, defaultAuthType LucidAuthType) LucidAuthType {
+	if authType, ok := c.authTypes[endpointOfPath(path)]; ok {
+		return authType
+	}
+
+	if defaultAuthType == LucidAuthTypeApiKey && c.apiKey == "" && c.HasOAuth2() {
+		return LucidAuthTypeOAuth2
+	}
+
+	return defaultAuthType
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuthTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    map[LucidEndpoint]LucidAuthType
+		wantErr string
+	}{
+		{
+			name:   "empty",
+			values: nil,
+			want:   map[LucidEndpoint]LucidAuthType{},
+		},
+		{
+			name:   "endpoints",
+			values: []string{"shares=oauth2", " users = API-KEY "},
+			want: map[LucidEndpoint]LucidAuthType{
+				LucidEndpointShares: LucidAuthTypeOAuth2,
+				LucidEndpointUsers:  LucidAuthTypeApiKey,
+			},
+		},
+		{
+			name:    "without auth type",
+			values:  []string{"shares"},
+			wantErr: "expected endpoint=auth-type",
+		},
+		{
+			name:    "unknown endpoint",
+			values:  []string{"comments=oauth2"},
+			wantErr: "unknown endpoint comments",
+		},
+		{
+			name:    "API key of an OAuth2 endpoint",
+			values:  []string{"audit-logs=api-key"},
+			wantErr: "only accepts OAuth2",
+		},
+		{
+			name:    "unknown auth type",
+			values:  []string{"shares=basic"},
+			wantErr: "unknown auth type basic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAuthTypes(tt.values)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEndpointOfPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want LucidEndpoint
+	}{
+		{path: GetUsersPath, want: LucidEndpointUsers},
+		{path: GetTeamsPath, want: LucidEndpointTeams},
+		{path: ListAuditLogsPath, want: LucidEndpointAuditLogs},
+		{path: fmt.Sprintf(FolderContentPath, "1"), want: LucidEndpointFolders},
+		{path: CreateFolderPath, want: LucidEndpointFolders},
+		{path: fmt.Sprintf(TransferDocumentOwnershipPath, "doc"), want: LucidEndpointDocuments},
+		{path: fmt.Sprintf(ListFolderUserCollaboratorsPath, "1"), want: LucidEndpointShares},
+		{path: fmt.Sprintf(DeleteDocumentShareLinkPath, "doc", "link"), want: LucidEndpointShares},
+		{path: TokenPath, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			require.Equal(t, tt.want, endpointOfPath(tt.path))
+		})
+	}
+}
+
+func TestAuthTypes(t *testing.T) {
+	ctx := context.Background()
+	oauth2 := &LucidChartOAuth2Options{ClientID: "client-id", ClientSecret: "client-secret", RefreshToken: "refresh-token"}
+
+	tests := []struct {
+		name   string
+		apiKey string
+		oauth2 *LucidChartOAuth2Options
+		opts   []ClientOption
+		want   map[string]string
+	}{
+		{
+			name:   "defaults",
+			apiKey: "api-key",
+			oauth2: oauth2,
+			want: map[string]string{
+				GetUsersPath:                  "Bearer token-1",
+				"/folders/1/contents":         "Bearer api-key",
+				"/documents/doc/shares/users": "Bearer api-key",
+			},
+		},
+		{
+			name:   "configured endpoints",
+			apiKey: "api-key",
+			oauth2: oauth2,
+			opts: []ClientOption{WithAuthTypes(map[LucidEndpoint]LucidAuthType{
+				LucidEndpointUsers:  LucidAuthTypeApiKey,
+				LucidEndpointShares: LucidAuthTypeOAuth2,
+			})},
+			want: map[string]string{
+				GetUsersPath:                  "Bearer api-key",
+				"/folders/1/contents":         "Bearer api-key",
+				"/documents/doc/shares/users": "Bearer token-1",
+			},
+		},
+		{
+			name:   "OAuth2 only",
+			oauth2: oauth2,
+			want: map[string]string{
+				GetUsersPath:                  "Bearer token-1",
+				"/folders/1/contents":         "Bearer token-1",
+				"/documents/doc/shares/users": "Bearer token-1",
+			},
+		},
+		{
+			name:   "API key only",
+			apiKey: "api-key",
+			want: map[string]string{
+				GetUsersPath:                  "Bearer api-key",
+				"/folders/1/contents":         "Bearer api-key",
+				"/documents/doc/shares/users": "Bearer api-key",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newAuthServer(t)
+
+			opts := append([]ClientOption{WithBaseUrl(ClientUrl(server.URL))}, tt.opts...)
+			c, err := NewLucidchartClient(ctx, tt.apiKey, tt.oauth2, opts...)
+			require.NoError(t, err)
+
+			_, _, err = c.ListUser(ctx, "")
+			require.NoError(t, err)
+
+			_, _, err = c.FolderContent(ctx, "1", "")
+			require.NoError(t, err)
+
+			_, _, err = c.ListDocumentUserCollaborators(ctx, "doc", "")
+			require.NoError(t, err)
+
+			got := map[string]string{}
+			for path, values := range server.paths() {
+				require.Len(t, values, 1, path)
+				got[path] = values[0]
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuthTypeWithoutCredential(t *testing.T) {
+	ctx := context.Background()
+	server := newAuthServer(t)
+
+	c, err := NewLucidchartClient(
+		ctx,
+		"",
+		&LucidChartOAuth2Options{ClientID: "client-id", ClientSecret: "client-secret", RefreshToken: "refresh-token"},
+		WithBaseUrl(ClientUrl(server.URL)),
+		WithAuthTypes(map[LucidEndpoint]LucidAuthType{LucidEndpointFolders: LucidAuthTypeApiKey}),
+	)
+	require.NoError(t, err)
+
+	_, _, err = c.FolderContent(ctx, "1", "")
+	require.ErrorContains(t, err, "needs an API key")
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"container/list"
+	"sync"
+)
+
+// defaultFolderContentCacheSize is the number of folder content pages kept in memory. The folder and
+// document builders list the same folder one right after the other, so only recent pages need to be kept.
+const defaultFolderContentCacheSize = 1000
+
+type folderContentCacheKey struct {
+	folderId  string
+	pageToken string
+}
+
+type folderContentCacheEntry struct {
+	key       folderContentCacheKey
+	content   []FolderContent
+	nextToken string
+}
+
+// folderContentCache is a LRU cache of folder content pages, keyed by folder id and page token. It lets
+// the folder and document builders share a single request for each page of a folder.
+type folderContentCache struct {
+	mutex   sync.Mutex
+	size    int
+	entries map[folderContentCacheKey]*list.Element
+	order   *list.List
+}
+
+func newFolderContentCache(size int) *folderContentCache {
+	return &folderContentCache{
+		size:    size,
+		entries: make(map[folderContentCacheKey]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *folderContentCache) get(folderId, pageToken string) ([]FolderContent, string, bool) {
+	if c == nil {
+		return nil, "", false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[folderContentCacheKey{folderId: folderId, pageToken: pageToken}]
+	if !ok {
+		return nil, "", false
+	}
+
+	c.order.MoveToFront(element)
+	entry := element.Value.(*folderContentCacheEntry)
+
+	return entry.content, entry.nextToken, true
+}
+
+func (c *folderContentCache) set(folderId, pageToken string, content []FolderContent, nextToken string) {
+	if c == nil || c.size <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := folderContentCacheKey{folderId: folderId, pageToken: pageToken}
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		element.Value = &folderContentCacheEntry{key: key, content: content, nextToken: nextToken}
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&folderContentCacheEntry{key: key, content: content, nextToken: nextToken})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*folderContentCacheEntry).key)
+	}
+}
+
+// reset drops every page, so that a new sync doesn't see the folders as a previous one listed them.
+func (c *folderContentCache) reset() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[folderContentCacheKey]*list.Element)
+	c.order.Init()
+}
+
+// drop drops the pages of the folders, and the pages listing any of them, once they were changed.
+func (c *folderContentCache) drop(ids ...string) {
+	if c == nil {
+		return
+	}
+
+	dropped := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		dropped[id] = true
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key, element := range c.entries {
+		if !dropped[key.folderId] && !listsAny(element.Value.(*folderContentCacheEntry).content, dropped) {
+			continue
+		}
+
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+func listsAny(content []FolderContent, ids map[string]bool) bool {
+	for i := range content {
+		if ids[content[i].ID()] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, server *httptest.Server) *LucidchartClient {
+	t.Helper()
+
+	uhttpClient, err := uhttp.NewBaseHttpClientWithContext(context.Background(), server.Client())
+	require.NoError(t, err)
+
+	return &LucidchartClient{
+		ctx:                context.Background(),
+		client:             uhttpClient,
+		apiKey:             "api-key",
+		baseUrl:            ClientUrl(server.URL),
+		folderContentCache: newFolderContentCache(defaultFolderContentCacheSize),
+	}
+}
+
+func TestFolderContentCache(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		content := []FolderContent{
+			{Id: 1, Type: "folder", Name: "folder"},
+			{Id: "doc", Type: "document", Name: "document"},
+		}
+
+		if r.URL.Query().Get("pageToken") == "" {
+			w.Header().Set("Link", fmt.Sprintf("<http://%s%s?pageToken=next>; rel=\"next\"", r.Host, r.URL.Path))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(content); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	// The folder builder and then the document builder list both pages of the same folder.
+	for i := 0; i < 2; i++ {
+		content, nextToken, err := c.FolderContent(ctx, "123", "")
+		require.NoError(t, err)
+		require.Len(t, content, 2)
+		require.Equal(t, "next", nextToken)
+
+		content, nextToken, err = c.FolderContent(ctx, "123", "next")
+		require.NoError(t, err)
+		require.Len(t, content, 2)
+		require.Equal(t, "", nextToken)
+	}
+
+	require.Equal(t, int32(2), calls.Load())
+
+	// The root folder is listed through both methods.
+	_, _, err := c.FolderContent(ctx, "root", "")
+	require.NoError(t, err)
+	_, _, err = c.RootFolderContent(ctx, "")
+	require.NoError(t, err)
+
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestFolderContentCacheEviction(t *testing.T) {
+	cache := newFolderContentCache(2)
+
+	cache.set("1", "", []FolderContent{{Id: "1"}}, "")
+	cache.set("2", "", []FolderContent{{Id: "2"}}, "")
+
+	// Reading the first folder makes the second one the least recently used.
+	_, _, ok := cache.get("1", "")
+	require.True(t, ok)
+
+	cache.set("3", "", []FolderContent{{Id: "3"}}, "")
+
+	_, _, ok = cache.get("2", "")
+	require.False(t, ok)
+
+	_, _, ok = cache.get("1", "")
+	require.True(t, ok)
+
+	_, _, ok = cache.get("3", "")
+	require.True(t, ok)
+}
+
+func TestFolderContentCacheDrop(t *testing.T) {
+	cache := newFolderContentCache(defaultFolderContentCacheSize)
+
+	cache.set("root", "", []FolderContent{{Id: 1, Type: "folder"}}, "")
+	cache.set("1", "", []FolderContent{{Id: 2, Type: "folder"}}, "next")
+	cache.set("1", "next", []FolderContent{{Id: "doc", Type: "document"}}, "")
+	cache.set("2", "", []FolderContent{}, "")
+
+	// Moving folder 2 drops its pages and the page of its previous parent listing it.
+	cache.drop("2", "root")
+
+	for _, key := range []folderContentCacheKey{{"root", ""}, {"1", ""}, {"2", ""}} {
+		_, _, ok := cache.get(key.folderId, key.pageToken)
+		require.False(t, ok, key)
+	}
+
+	_, _, ok := cache.get("1", "next")
+	require.True(t, ok)
+
+	cache.reset()
+
+	_, _, ok = cache.get("1", "next")
+	require.False(t, ok)
+}
+
+func TestFolderContentCacheSync(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			_, _ = w.Write([]byte(`{"id":34,"type":"folder","name":"Project"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	list := func() {
+		_, _, err := c.FolderContent(ctx, "12", "")
+		require.NoError(t, err)
+	}
+
+	list()
+	list()
+	require.Equal(t, int32(1), calls.Load())
+
+	// A new sync lists the folders again.
+	c.StartSync(ctx)
+	list()
+	require.Equal(t, int32(2), calls.Load())
+
+	// So does a folder created in it.
+	_, err := c.CreateFolder(ctx, "Project", "12")
+	require.NoError(t, err)
+	list()
+	require.Equal(t, int32(4), calls.Load())
+
+	// And a document restored, whose parent isn't known.
+	require.NoError(t, c.RestoreDocument(ctx, "doc"))
+	list()
+	require.Equal(t, int32(6), calls.Load())
+}
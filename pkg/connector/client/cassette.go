@@ -0,0 +1,340 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// CassetteMode selects whether the client records its requests into a cassette or replays them from one.
+type CassetteMode string
+
+const (
+	// CassetteRecord sends the requests to Lucid and appends each request and response to the cassette.
+	CassetteRecord CassetteMode = "record"
+	// CassetteReplay answers the requests from the cassette, without any network access.
+	CassetteReplay CassetteMode = "replay"
+)
+
+const redacted = "REDACTED"
+
+// cassetteSecretKeys are the keys of the JSON values replaced by redacted, wherever they are.
+var cassetteSecretKeys = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"client_secret": true,
+	"passcode":      true,
+	"acceptUrl":     true,
+}
+
+// cassetteRecordedHeaders are the response headers kept in the cassette, the others may identify the account.
+var cassetteRecordedHeaders = []string{"Content-Type", "Link", "Retry-After"}
+
+// cassetteInteraction is a request and its response, one per line of the cassette.
+type cassetteInteraction struct {
+	Method   string      `json:"method"`
+	Uri      string      `json:"uri"`
+	Request  string      `json:"request,omitempty"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header,omitempty"`
+	Response string      `json:"response,omitempty"`
+}
+
+func (i *cassetteInteraction) key() string {
+	return cassetteKey(i.Method, i.Uri)
+}
+
+// cassetteVolatileQuery are the query parameters left out of the keys of the interactions, by path, because
+// they depend on the time of the request and would never match on replay.
+var cassetteVolatileQuery = map[string][]string{
+	ListAuditLogsPath: {"from"},
+}
+
+// cassetteKey returns the key matching a request with its interactions, its method and URI without the
+// volatile query parameters.
+func cassetteKey(method, uri string) string {
+	u, err := url.ParseRequestURI(uri)
+	if err != nil || len(cassetteVolatileQuery[u.Path]) == 0 {
+		return method + " " + uri
+	}
+
+	query := u.Query()
+	for _, param := range cassetteVolatileQuery[u.Path] {
+		query.Del(param)
+	}
+	u.RawQuery = query.Encode()
+
+	return method + " " + u.RequestURI()
+}
+
+// WithCassette records the requests of the client into the cassette file, or replays them from it. The
+// credentials and the personal details of the users are redacted from the recorded requests and responses.
+func WithCassette(mode CassetteMode, path string) ClientOption {
+	return func(c *LucidchartClient) {
+		c.cassetteMode = mode
+		c.cassettePath = path
+	}
+}
+
+// newCassetteTransport returns the transport recording into, or replaying from, the cassette file.
+func newCassetteTransport(mode CassetteMode, path string, next http.RoundTripper) (http.RoundTripper, error) {
+	switch mode {
+	case CassetteRecord:
+		return newCassetteRecorder(path, next)
+	case CassetteReplay:
+		return newCassettePlayer(path)
+	default:
+		return nil, fmt.Errorf("baton-lucidchart: unknown cassette mode %s", mode)
+	}
+}
+
+// cassetteRecorder sends the requests and appends the redacted interactions to the cassette file.
+type cassetteRecorder struct {
+	next http.RoundTripper
+	path string
+	// key pseudonymizes the personal details, so that they stay consistent across the cassette without
+	// being guessable from it.
+	key []byte
+
+	mutex sync.Mutex
+}
+
+func newCassetteRecorder(path string, next http.RoundTripper) (*cassetteRecorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	// The cassette is written from scratch by every run.
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		return nil, err
+	}
+
+	return &cassetteRecorder{
+		next: next,
+		path: path,
+		key:  key,
+	}, nil
+}
+
+func (r *cassetteRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	interaction := cassetteInteraction{
+		Method:   req.Method,
+		Uri:      req.URL.RequestURI(),
+		Request:  r.redact(requestBody),
+		Status:   resp.StatusCode,
+		Header:   http.Header{},
+		Response: r.redact(responseBody),
+	}
+
+	// The token requests only hold credentials.
+	if strings.HasSuffix(req.URL.Path, TokenPath) && interaction.Request != "" {
+		interaction.Request = redacted
+	}
+
+	for _, name := range cassetteRecordedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			interaction.Header.Set(name, value)
+		}
+	}
+
+	err = r.write(&interaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// write appends the interaction to the cassette, as a JSON line.
+func (r *cassetteRecorder) write(interaction *cassetteInteraction) error {
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+
+	return err
+}
+
+// redact removes the secrets and the personal details from a JSON body. Bodies that aren't JSON are
+// dropped, as they can't be redacted.
+func (r *cassetteRecorder) redact(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return redacted
+	}
+
+	redactedBody, err := json.Marshal(r.redactValue(value))
+	if err != nil {
+		return redacted
+	}
+
+	return string(redactedBody)
+}
+
+func (r *cassetteRecorder) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		_, isPerson := v["email"]
+		if v["type"] == OwnerTypeUser {
+			isPerson = true
+		}
+		for key, item := range v {
+			switch {
+			case cassetteSecretKeys[key]:
+				v[key] = redacted
+			case key == "email":
+				v[key] = r.pseudonym(item) + "@example.com"
+			case isPerson && (key == "name" || key == "usernames"):
+				v[key] = r.pseudonym(item)
+			default:
+				v[key] = r.redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactValue(item)
+		}
+	}
+
+	return value
+}
+
+// pseudonym returns the same replacement for the same value, so that the cassette keeps its shape.
+func (r *cassetteRecorder) pseudonym(value interface{}) string {
+	mac := hmac.New(sha256.New, r.key)
+	_, _ = fmt.Fprint(mac, value)
+
+	return "user-" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// cassettePlayer answers the requests from the interactions of the cassette with the same key, see cassetteKey,
+// in the order they were recorded. The last one is repeated once they have all been replayed.
+type cassettePlayer struct {
+	mutex        sync.Mutex
+	interactions map[string][]*cassetteInteraction
+}
+
+func newCassettePlayer(path string) (*cassettePlayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p := &cassettePlayer{
+		interactions: make(map[string][]*cassetteInteraction),
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		interaction := &cassetteInteraction{}
+		if err := json.Unmarshal(scanner.Bytes(), interaction); err != nil {
+			return nil, fmt.Errorf("baton-lucidchart: invalid cassette line %d: %w", line, err)
+		}
+
+		p.interactions[interaction.key()] = append(p.interactions[interaction.key()], interaction)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *cassettePlayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key := cassetteKey(req.Method, req.URL.RequestURI())
+
+	p.mutex.Lock()
+	interactions := p.interactions[key]
+	if len(interactions) > 1 {
+		p.interactions[key] = interactions[1:]
+	}
+	p.mutex.Unlock()
+
+	if len(interactions) == 0 {
+		return nil, fmt.Errorf("baton-lucidchart: no interaction recorded in the cassette for %s", key)
+	}
+
+	interaction := interactions[0]
+
+	header := interaction.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewBufferString(interaction.Response)),
+		ContentLength: int64(len(interaction.Response)),
+		Request:       req,
+	}, nil
+}
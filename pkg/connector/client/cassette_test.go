@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassette(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var body interface{}
+		switch {
+		case r.URL.Path == TokenPath:
+			body = map[string]interface{}{"access_token": "secret-access-token", "refresh_token": "secret-refresh-token", "expires": 4102444800000}
+		case r.URL.Path == "/users" && r.URL.Query().Get("pageToken") == "":
+			w.Header().Set("Link", fmt.Sprintf("<http://%s/users?pageToken=2>; rel=\"next\"", r.Host))
+			body = []User{{UserId: 1, Email: "jane@example.org", Name: "Jane Doe"}}
+		case r.URL.Path == "/users":
+			body = []User{{UserId: 2, Email: "john@example.org", Name: "John Doe"}}
+		case r.URL.Path == ListAuditLogsPath:
+			body = []AuditLogEvent{{EventId: "1", EventType: "documentCreated"}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			body = map[string]string{"code": "notFound"}
+		}
+
+		require.NoError(t, json.NewEncoder(w).Encode(body))
+	}))
+
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	opts := &LucidChartOAuth2Options{ClientID: "client-id", ClientSecret: "secret-client", RefreshToken: "secret-refresh-token"}
+
+	listUsers := func(c *LucidchartClient) []User {
+		var users []User
+		pageToken := ""
+		for {
+			page, nextToken, err := c.ListUser(ctx, pageToken)
+			require.NoError(t, err)
+
+			users = append(users, page...)
+			if nextToken == "" {
+				return users
+			}
+
+			pageToken = nextToken
+		}
+	}
+
+	recorder, err := NewLucidchartClient(ctx, "secret-api-key", opts, WithBaseUrl(ClientUrl(server.URL)), WithCassette(CassetteRecord, path))
+	require.NoError(t, err)
+
+	recorded := listUsers(recorder)
+	require.Len(t, recorded, 2)
+	require.Equal(t, "jane@example.org", recorded[0].Email)
+
+	events, _, err := recorder.ListAuditLogs(ctx, time.Now().Add(-time.Hour), 0, "")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	cassette, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	for _, secret := range []string{"secret", "jane@example.org", "Jane Doe", "john@example.org", "John Doe"} {
+		require.NotContains(t, string(cassette), secret)
+	}
+
+	// The replay doesn't need the server.
+	server.Close()
+
+	player, err := NewLucidchartClient(ctx, "api-key", opts, WithBaseUrl(ClientUrl(server.URL)), WithCassette(CassetteReplay, path))
+	require.NoError(t, err)
+
+	replayed := listUsers(player)
+	require.Len(t, replayed, 2)
+	require.Equal(t, 1, replayed[0].UserId)
+	require.Equal(t, 2, replayed[1].UserId)
+	require.NotEqual(t, replayed[0].Email, replayed[1].Email)
+
+	// The audit logs are replayed whatever the time they are listed from.
+	events, _, err = player.ListAuditLogs(ctx, time.Now(), 0, "")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	_, _, err = player.ListTeams(ctx, "")
+	require.ErrorContains(t, err, "no interaction recorded in the cassette for GET /teams")
+}
+
+func TestCassetteRedaction(t *testing.T) {
+	recorder, err := newCassetteRecorder(filepath.Join(t.TempDir(), "cassette.jsonl"), nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "empty",
+			body: "",
+			want: "",
+		},
+		{
+			name: "not json",
+			body: "<html>jane@example.org</html>",
+			want: redacted,
+		},
+		{
+			name: "share link",
+			body: `{"shareLinkId":"abc","passcode":"1234","acceptUrl":"https://lucid.app/invitations/accept/abc"}`,
+			want: `{"acceptUrl":"REDACTED","passcode":"REDACTED","shareLinkId":"abc"}`,
+		},
+		{
+			name: "folder",
+			body: `{"id":1,"name":"Projects","type":"folder"}`,
+			want: `{"id":1,"name":"Projects","type":"folder"}`,
+		},
+		{
+			name: "document owner",
+			body: `{"owner":{"id":1,"name":"Jane Doe","type":"user"}}`,
+			want: fmt.Sprintf(`{"owner":{"id":1,"name":"%s","type":"user"}}`, recorder.pseudonym("Jane Doe")),
+		},
+		{
+			name: "users",
+			body: `[{"email":"jane@example.org","name":"Jane Doe","userId":1}]`,
+			want: fmt.Sprintf(`[{"email":"%s@example.com","name":"%s","userId":1}]`, recorder.pseudonym("jane@example.org"), recorder.pseudonym("Jane Doe")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, recorder.redact([]byte(tt.body)))
+		})
+	}
+}
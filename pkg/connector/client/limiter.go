@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRequestsPerSecond caps the requests sent by the client, whether they come from the syncer, the
+// prefetch or the work the connector does in the background. No limit when 0.
+func WithRequestsPerSecond(requestsPerSecond int) ClientOption {
+	return func(c *LucidchartClient) {
+		if requestsPerSecond > 0 {
+			c.limiter = newRateLimiter(requestsPerSecond)
+		}
+	}
+}
+
+// rateLimiter spaces out requests evenly, each request reserving the next free slot. A nil rateLimiter
+// doesn't limit anything.
+type rateLimiter struct {
+	interval time.Duration
+
+	mutex sync.Mutex
+	next  time.Time
+}
+
+func newRateLimiter(requestsPerSecond int) *rateLimiter {
+	return &rateLimiter{
+		interval: time.Second / time.Duration(requestsPerSecond),
+	}
+}
+
+// wait waits for the slot of a request, or returns the error of the context when it is done first.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mutex.Lock()
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	delay := r.next.Sub(now)
+	r.next = r.next.Add(r.interval)
+	r.mutex.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	var limiter *rateLimiter
+	require.NoError(t, limiter.wait(context.Background()))
+
+	limiter = newRateLimiter(100)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.wait(context.Background()))
+	}
+
+	// The first request goes right away, the next ones wait for their slot.
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	slow := newRateLimiter(1)
+	require.NoError(t, slow.wait(context.Background()))
+	require.ErrorIs(t, slow.wait(ctx), context.Canceled)
+}
+
+func TestSyncContext(t *testing.T) {
+	server, _ := newTreeServer(t)
+	c := newTestClient(t, server)
+
+	require.Equal(t, c.ctx, c.SyncContext())
+
+	c.StartSync(context.Background())
+	first := c.SyncContext()
+	require.NoError(t, first.Err())
+
+	c.StartSync(context.Background())
+	require.ErrorIs(t, first.Err(), context.Canceled)
+	require.NoError(t, c.SyncContext().Err())
+}
@@ -0,0 +1,222 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// link is a link of a Link header, as defined by RFC 8288.
+type link struct {
+	uri string
+	// params are the parameters of the link, by lowercase name. Only the first occurrence of each
+	// parameter is kept, as the RFC requires for rel.
+	params map[string]string
+}
+
+// rels returns the lowercase relation types of the link, rel holding a space separated list of them.
+func (l *link) rels() []string {
+	return strings.Fields(strings.ToLower(l.params["rel"]))
+}
+
+// hasRel returns whether the link has the relation type.
+func (l *link) hasRel(rel string) bool {
+	for _, r := range l.rels() {
+		if r == rel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseLinkHeader parses the value of Link headers, several headers being joined by commas:
+//
+//	Link       = #link-value
+//	link-value = "<" URI-Reference ">" *( OWS ";" OWS link-param )
+//	link-param = token BWS [ "=" BWS ( token / quoted-string ) ]
+func parseLinkHeader(header string) ([]link, error) {
+	p := &linkParser{input: header}
+
+	var links []link
+	for {
+		p.skipSpaces()
+		if p.done() {
+			return links, nil
+		}
+
+		// Empty list elements are allowed by the # rule.
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+
+		l, err := p.linkValue()
+		if err != nil {
+			return nil, err
+		}
+
+		links = append(links, l)
+
+		p.skipSpaces()
+		if p.done() {
+			return links, nil
+		}
+
+		if p.peek() != ',' {
+			return nil, p.errorf("expected ',' after link")
+		}
+		p.pos++
+	}
+}
+
+// linkParser is a cursor over a Link header.
+type linkParser struct {
+	input string
+	pos   int
+}
+
+func (p *linkParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *linkParser) peek() byte {
+	return p.input[p.pos]
+}
+
+func (p *linkParser) skipSpaces() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *linkParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid link header at %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *linkParser) linkValue() (link, error) {
+	if p.peek() != '<' {
+		return link{}, p.errorf("expected '<'")
+	}
+	p.pos++
+
+	end := strings.IndexByte(p.input[p.pos:], '>')
+	if end < 0 {
+		return link{}, p.errorf("missing '>'")
+	}
+
+	l := link{
+		uri:    strings.TrimSpace(p.input[p.pos : p.pos+end]),
+		params: make(map[string]string),
+	}
+	p.pos += end + 1
+
+	for {
+		p.skipSpaces()
+		if p.done() || p.peek() == ',' {
+			return l, nil
+		}
+
+		if p.peek() != ';' {
+			return link{}, p.errorf("expected ';' or ','")
+		}
+		p.pos++
+		p.skipSpaces()
+
+		// A trailing ';' is tolerated, as some servers send one.
+		if p.done() || p.peek() == ',' {
+			return l, nil
+		}
+
+		name, value, err := p.linkParam()
+		if err != nil {
+			return link{}, err
+		}
+
+		if _, ok := l.params[name]; !ok {
+			l.params[name] = value
+		}
+	}
+}
+
+func (p *linkParser) linkParam() (string, string, error) {
+	name := p.token()
+	if name == "" {
+		return "", "", p.errorf("expected a parameter name")
+	}
+
+	// Extended parameters, like title*, are kept under their name with the star.
+	name = strings.ToLower(name)
+
+	p.skipSpaces()
+	if p.done() || p.peek() != '=' {
+		return name, "", nil
+	}
+	p.pos++
+	p.skipSpaces()
+
+	if p.done() {
+		return "", "", p.errorf("expected a value for %s", name)
+	}
+
+	if p.peek() == '"' {
+		value, err := p.quotedString()
+		if err != nil {
+			return "", "", err
+		}
+
+		return name, value, nil
+	}
+
+	value := p.token()
+	if value == "" {
+		return "", "", p.errorf("expected a value for %s", name)
+	}
+
+	return name, value, nil
+}
+
+// token reads a token, as defined by RFC 7230.
+func (p *linkParser) token() string {
+	start := p.pos
+	for !p.done() && isTokenChar(p.peek()) {
+		p.pos++
+	}
+
+	return p.input[start:p.pos]
+}
+
+// quotedString reads a quoted string, unescaping its quoted pairs.
+func (p *linkParser) quotedString() (string, error) {
+	p.pos++
+
+	var value strings.Builder
+	for !p.done() {
+		c := p.peek()
+		p.pos++
+
+		switch c {
+		case '"':
+			return value.String(), nil
+		case '\\':
+			if p.done() {
+				return "", errors.New("invalid link header: unterminated quoted pair")
+			}
+			value.WriteByte(p.peek())
+			p.pos++
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	return "", errors.New("invalid link header: unterminated quoted string")
+}
+
+func isTokenChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Header        string
+		ExpectedLinks []link
+		ExpectedError bool
+	}{
+		{
+			Name:   "empty",
+			Header: "",
+		},
+		{
+			Name:   "single link",
+			Header: `<https://api.lucid.co/users?pageToken=a>; rel="next"`,
+			ExpectedLinks: []link{
+				{uri: "https://api.lucid.co/users?pageToken=a", params: map[string]string{"rel": "next"}},
+			},
+		},
+		{
+			Name:   "several links",
+			Header: `<https://api.lucid.co/users?pageToken=a>; rel="prev", <https://api.lucid.co/users?pageToken=b>; rel="next"`,
+			ExpectedLinks: []link{
+				{uri: "https://api.lucid.co/users?pageToken=a", params: map[string]string{"rel": "prev"}},
+				{uri: "https://api.lucid.co/users?pageToken=b", params: map[string]string{"rel": "next"}},
+			},
+		},
+		{
+			Name:   "comma and semicolon in the uri",
+			Header: `</users;v=1?ids=1,2>; rel="next", </users?pageToken=b>`,
+			ExpectedLinks: []link{
+				{uri: "/users;v=1?ids=1,2", params: map[string]string{"rel": "next"}},
+				{uri: "/users?pageToken=b", params: map[string]string{}},
+			},
+		},
+		{
+			Name:   "parameters",
+			Header: `<https://api.lucid.co/users> ; REL = next ; title="a \"quoted\", title; here" ; crossorigin`,
+			ExpectedLinks: []link{
+				{uri: "https://api.lucid.co/users", params: map[string]string{"rel": "next", "title": `a "quoted", title; here`, "crossorigin": ""}},
+			},
+		},
+		{
+			Name:   "first relation wins",
+			Header: `</users?pageToken=a>; rel="next"; rel="prev"`,
+			ExpectedLinks: []link{
+				{uri: "/users?pageToken=a", params: map[string]string{"rel": "next"}},
+			},
+		},
+		{
+			Name:   "empty elements and trailing semicolon",
+			Header: `, </users?pageToken=a>; rel=next;, ,`,
+			ExpectedLinks: []link{
+				{uri: "/users?pageToken=a", params: map[string]string{"rel": "next"}},
+			},
+		},
+		{
+			Name:          "missing angle brackets",
+			Header:        `https://api.lucid.co/users; rel="next"`,
+			ExpectedError: true,
+		},
+		{
+			Name:          "unterminated uri",
+			Header:        `<https://api.lucid.co/users; rel="next"`,
+			ExpectedError: true,
+		},
+		{
+			Name:          "unterminated quoted string",
+			Header:        `<https://api.lucid.co/users>; rel="next`,
+			ExpectedError: true,
+		},
+		{
+			Name:          "missing value",
+			Header:        `<https://api.lucid.co/users>; rel=`,
+			ExpectedError: true,
+		},
+		{
+			Name:          "garbage after link",
+			Header:        `<https://api.lucid.co/users> rel="next"`,
+			ExpectedError: true,
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.Name, func(t *testing.T) {
+			links, err := parseLinkHeader(s.Header)
+			if s.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, s.ExpectedLinks, links)
+		})
+	}
+}
+
+func FuzzExtractPageToken(f *testing.F) {
+	f.Add(``)
+	f.Add(`<https://api.lucid.co/users?pageToken=a>; rel="next"`)
+	f.Add(`<https://api.lucid.co/users?pageSize=1&pageToken=a>; rel="prev", <https://api.lucid.co/users?pageToken=b>; rel=next`)
+	f.Add(`<https://api.lucid.co/users?ids=1,2;3&pageToken=a>; title="a, \"b\"; c"; rel="last next"`)
+
+	f.Fuzz(func(t *testing.T, header string) {
+		token, err := extractPageToken(header)
+		if err != nil || token == "" {
+			return
+		}
+
+		// A token always leads to a request for the next page.
+		req, err := http.NewRequest(http.MethodGet, "https://api.lucid.co/users", nil)
+		require.NoError(t, err)
+
+		addPageToken(req, token)
+		require.NotEmpty(t, req.URL.Query().Get("pageToken"))
+	})
+}
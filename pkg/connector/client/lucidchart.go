@@ -1,13 +1,15 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
-
-	"go.uber.org/zap"
+	"sync"
 
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
@@ -15,13 +17,11 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-var UserFolderRoles = []string{
-	"owner",
-	"editandshare",
-	"edit",
-	"comment",
-	"view",
-}
+const (
+	ProductLucidchart = "lucidchart"
+	ProductLucidspark = "lucidspark"
+	ProductLucidscale = "lucidscale"
+)
 
 type LucidAuthType string
 
@@ -36,12 +36,49 @@ var LucidchartApiFedRampUrl ClientUrl = "https://api.lucidgov.app"
 var LucidchartApiUrl ClientUrl = "https://api.lucid.co"
 
 type LucidchartClient struct {
-	client         *uhttp.BaseHttpClient
+	// ctx is the context the client was created with, which outlives the requests of the syncer. The work
+	// done in the background of a sync runs in syncCtx, derived from it and cancelled once the next sync starts.
+	ctx        context.Context
+	syncMutex  sync.Mutex
+	syncCtx    context.Context
+	syncCancel context.CancelFunc
+	client     *uhttp.BaseHttpClient
+	// limiter caps every request of the client, nil when there is no limit.
+	limiter *rateLimiter
+	// lucidCharToken is nil when the client only has an API key, and apiKey is empty when it only has OAuth2.
 	lucidCharToken *LucidChartOAuth2
 	apiKey         string
+	baseUrl        ClientUrl
+	// authTypes are the auth types configured for endpoints, instead of their default one.
+	authTypes map[LucidEndpoint]LucidAuthType
+
+	// cassetteMode and cassettePath are set to record the requests into a cassette, or replay them from it.
+	cassetteMode CassetteMode
+	cassettePath string
+
+	// pageSize is the number of items requested per page, Lucid's default when 0.
+	pageSize int
+
+	// prefetcher lists the folder tree ahead of the syncer when the prefetch is enabled.
+	prefetcher *prefetcher
+
+	folderContentCache *folderContentCache
+}
+
+// ClientOption configures the optional behaviors of the client.
+type ClientOption func(*LucidchartClient)
+
+// WithBaseUrl sends the requests to another Lucid API, like the FedRAMP one or a fake one in tests.
+func WithBaseUrl(baseUrl ClientUrl) ClientOption {
+	return func(c *LucidchartClient) {
+		c.baseUrl = baseUrl
+	}
 }
 
-func NewLucidchartClient(ctx context.Context, apiKey string, opts *LucidChartOAuth2Options) (*LucidchartClient, error) {
+// NewLucidchartClient returns a client authorized with the API key, and with OAuth2 tokens for the endpoints
+// that need them. Without OAuth2 options, the client only has the API key and those endpoints fail. Without
+// an API key, every request is authorized with OAuth2.
+func NewLucidchartClient(ctx context.Context, apiKey string, opts *LucidChartOAuth2Options, clientOpts ...ClientOption) (*LucidchartClient, error) {
 	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
 	if err != nil {
 		return nil, err
@@ -52,16 +89,82 @@ func NewLucidchartClient(ctx context.Context, apiKey string, opts *LucidChartOAu
 		return nil, err
 	}
 
-	lucidCharToken, err := NewLucidChartOAuth2(ctx, opts)
+	c := &LucidchartClient{
+		ctx:                ctx,
+		client:             uhttpClient,
+		apiKey:             apiKey,
+		baseUrl:            LucidchartApiUrl,
+		folderContentCache: newFolderContentCache(defaultFolderContentCacheSize),
+	}
+
+	for _, opt := range clientOpts {
+		opt(c)
+	}
+
+	if c.cassetteMode != "" {
+		httpClient.Transport, err = newCassetteTransport(c.cassetteMode, c.cassettePath, httpClient.Transport)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts == nil {
+		return c, nil
+	}
+
+	tokenOpts := *opts
+	if tokenOpts.BaseUrl == "" {
+		tokenOpts.BaseUrl = c.baseUrl
+	}
+
+	// The tokens are requested through the cassette too, so that a replay doesn't need any network.
+	if c.cassetteMode != "" {
+		tokenOpts.transport = httpClient.Transport
+	}
+
+	c.lucidCharToken, err = NewLucidChartOAuth2(ctx, &tokenOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	return &LucidchartClient{
-		client:         uhttpClient,
-		lucidCharToken: lucidCharToken,
-		apiKey:         apiKey,
-	}, nil
+	return c, nil
+}
+
+// StartSync cancels the background work of the previous sync and drops the folder content it cached, the
+// client living as long as the connector, and restarts the prefetch of the folder tree if it is enabled.
+// The pages are then requested from Lucid again, as the client doesn't use the HTTP cache of the SDK, see do.
+func (c *LucidchartClient) StartSync(_ context.Context) {
+	c.syncMutex.Lock()
+	if c.syncCancel != nil {
+		c.syncCancel()
+	}
+	c.syncCtx, c.syncCancel = context.WithCancel(c.ctx)
+	syncCtx := c.syncCtx
+	c.syncMutex.Unlock()
+
+	c.folderContentCache.reset()
+
+	if c.prefetcher != nil {
+		c.prefetcher.restart(syncCtx)
+	}
+}
+
+// SyncContext returns the context of the current sync, which the work done in the background of the sync
+// must run in, so that it stops once the next sync starts. It is the context of the client until a sync starts.
+func (c *LucidchartClient) SyncContext() context.Context {
+	c.syncMutex.Lock()
+	defer c.syncMutex.Unlock()
+
+	if c.syncCtx == nil {
+		return c.ctx
+	}
+
+	return c.syncCtx
+}
+
+// HasOAuth2 returns whether the client has OAuth2 credentials, the endpoints that need them failing otherwise.
+func (c *LucidchartClient) HasOAuth2() bool {
+	return c.lucidCharToken != nil
 }
 
 func (c *LucidchartClient) newRequest(
@@ -81,8 +184,12 @@ func (c *LucidchartClient) newRequest(
 
 	var accessToken string
 
-	switch authType {
+	switch c.authType(path, authType) {
 	case LucidAuthTypeOAuth2:
+		if !c.HasOAuth2() {
+			return nil, status.Errorf(codes.FailedPrecondition, "baton-lucidchart: %s needs OAuth2 credentials, only an API key is configured", path)
+		}
+
 		token, err := c.lucidCharToken.GetToken(ctx)
 		if err != nil {
 			return nil, err
@@ -90,6 +197,10 @@ func (c *LucidchartClient) newRequest(
 		accessToken = token.AccessToken
 
 	case LucidAuthTypeApiKey:
+		if c.apiKey == "" {
+			return nil, status.Errorf(codes.FailedPrecondition, "baton-lucidchart: %s needs an API key, only OAuth2 credentials are configured", path)
+		}
+
 		accessToken = c.apiKey
 	}
 
@@ -135,17 +246,25 @@ func (c *LucidchartClient) doRequest(
 		options = append(options, uhttp.WithResponse(&res))
 	}
 
-	resp, err = c.client.Do(req.WithContext(ctx), options...)
+	err = c.limiter.wait(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err = c.do(req.WithContext(ctx), options...)
 	if err != nil {
-		if !isRetryToken && status.Code(err) == codes.Unauthenticated {
-			token, errToken := c.lucidCharToken.GetToken(ctx)
+		// A rejected access token may have been revoked before its expiration, it is renewed once. API key
+		// requests aren't retried, another key wouldn't be any different.
+		authorization := req.Header.Get("Authorization")
+		if !isRetryToken && c.HasOAuth2() && authorization != "Bearer "+c.apiKey && status.Code(err) == codes.Unauthenticated {
+			token, errToken := c.lucidCharToken.renewToken(ctx, strings.TrimPrefix(authorization, "Bearer "))
 			if errToken != nil {
 				return "", errors.Join(err, errToken)
 			}
 
-			l.Debug("Retrying request with new token", zap.String("token", token.AccessToken))
+			l.Debug("baton-lucidchart: retrying request with a renewed token")
 
-			req.Header.Set("Authorization", token.AccessToken)
+			req.Header.Set("Authorization", "Bearer "+token.AccessToken)
 
 			return c.doRequest(ctx, req, res, true)
 		}
@@ -154,7 +273,8 @@ func (c *LucidchartClient) doRequest(
 	}
 	defer resp.Body.Close()
 
-	nextToken := resp.Header.Get("Link")
+	// Several Link headers are equivalent to a single one joining them with commas.
+	nextToken := strings.Join(resp.Header.Values("Link"), ", ")
 
 	if nextToken != "" {
 		nextToken, err = extractPageToken(nextToken)
@@ -168,31 +288,118 @@ func (c *LucidchartClient) doRequest(
 	return "", nil
 }
 
-func extractPageToken(token string) (string, error) {
-	splitResult := strings.Split(token, ";")
+// do sends a request like uhttp.BaseHttpClient.Do, but without its HTTP cache, which keeps the responses of GET
+// requests for an hour. The connector reads the state of Lucid right before changing it, and each sync must
+// see the changes made since the previous one, so every request reaches Lucid. The folder content listed by
+// a sync is cached by folderContentCache instead, which is reset with each sync and dropped on changes.
+func (c *LucidchartClient) do(req *http.Request, options ...uhttp.DoOption) (*http.Response, error) {
+	resp, err := c.client.HttpClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && urlErr.Timeout() {
+			return nil, uhttp.WrapErrors(codes.DeadlineExceeded, fmt.Sprintf("request timeout: %v", urlErr.URL), urlErr)
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.Error(codes.DeadlineExceeded, "request timeout")
+		}
+
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, uhttp.WrapErrors(codes.Unavailable, "failed to read the response", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
 
-	if len(splitResult) < 2 {
-		return "", errors.New("expected two parts in the token")
+	wresp := uhttp.WrapperResponse{
+		Header:     resp.Header,
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Body:       body,
 	}
 
-	value := strings.Trim(strings.TrimSpace(splitResult[0]), "<> ")
+	var optErrs []error
+	for _, option := range options {
+		if optErr := option(&wresp); optErr != nil {
+			optErrs = append(optErrs, optErr)
+		}
+	}
 
-	valueUrl, err := url.Parse(value)
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout:
+		return resp, uhttp.WrapErrorsWithRateLimitInfo(codes.DeadlineExceeded, resp, optErrs...)
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return resp, uhttp.WrapErrorsWithRateLimitInfo(codes.Unavailable, resp, optErrs...)
+	case resp.StatusCode == http.StatusNotFound:
+		return resp, uhttp.WrapErrorsWithRateLimitInfo(codes.NotFound, resp, optErrs...)
+	case resp.StatusCode == http.StatusUnauthorized:
+		return resp, uhttp.WrapErrorsWithRateLimitInfo(codes.Unauthenticated, resp, optErrs...)
+	case resp.StatusCode == http.StatusForbidden:
+		return resp, uhttp.WrapErrorsWithRateLimitInfo(codes.PermissionDenied, resp, optErrs...)
+	case resp.StatusCode == http.StatusConflict:
+		return resp, uhttp.WrapErrorsWithRateLimitInfo(codes.AlreadyExists, resp, optErrs...)
+	case resp.StatusCode == http.StatusNotImplemented:
+		return resp, uhttp.WrapErrorsWithRateLimitInfo(codes.Unimplemented, resp, optErrs...)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return resp, uhttp.WrapErrorsWithRateLimitInfo(codes.Unknown, resp, append(optErrs, fmt.Errorf("unexpected status code: %d", resp.StatusCode))...)
+	}
+
+	return resp, errors.Join(optErrs...)
+}
+
+// extractPageToken returns the page token of the next link of a Link header, or an empty token when there
+// is no next page. When Lucid adds other query parameters to the next link, the token holds all of them
+// encoded as a query, so that addPageToken sends them back.
+func extractPageToken(header string) (string, error) {
+	links, err := parseLinkHeader(header)
 	if err != nil {
 		return "", err
 	}
 
-	query := valueUrl.Query()
-	pageToken := query.Get("pageToken")
+	for _, l := range links {
+		if !l.hasRel("next") {
+			continue
+		}
+
+		nextUrl, err := url.Parse(l.uri)
+		if err != nil {
+			return "", err
+		}
+
+		query := nextUrl.Query()
+		pageToken := query.Get("pageToken")
+		if pageToken == "" {
+			return "", errors.New("next link without a page token")
+		}
 
-	return pageToken, nil
+		if len(query) == 1 && len(query["pageToken"]) == 1 {
+			return pageToken, nil
+		}
+
+		return query.Encode(), nil
+	}
+
+	return "", nil
 }
 
+// addPageToken sets the page token on the request, along with the other query parameters of the next link
+// the token holds.
 func addPageToken(req *http.Request, pageToken string) {
-	if pageToken != "" {
-		query := req.URL.Query()
-		query.Add("pageToken", pageToken)
+	if pageToken == "" {
+		return
+	}
+
+	query := req.URL.Query()
 
-		req.URL.RawQuery = query.Encode()
+	if params, err := url.ParseQuery(pageToken); err == nil && params.Get("pageToken") != "" {
+		for key, values := range params {
+			query[key] = values
+		}
+	} else {
+		query.Set("pageToken", pageToken)
 	}
+
+	req.URL.RawQuery = query.Encode()
 }
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -11,6 +12,7 @@ func TestExtractPageToken(t *testing.T) {
 		Name          string
 		Link          string
 		ExpectedToken string
+		ExpectedError bool
 	}{
 		{
 			Name:          "empty link",
@@ -20,15 +22,104 @@ func TestExtractPageToken(t *testing.T) {
 		{
 			Name:          "link with token",
 			Link:          "<https://api.lucid.co/users?pageSize=1&pageToken=eyJvIjoiMSJ9>; rel=\"next\"",
+			ExpectedToken: "pageSize=1&pageToken=eyJvIjoiMSJ9",
+		},
+		{
+			Name:          "link with only a token",
+			Link:          "<https://api.lucid.co/users?pageToken=eyJvIjoiMSJ9>; rel=\"next\"",
 			ExpectedToken: "eyJvIjoiMSJ9",
 		},
+		{
+			Name:          "previous and next links",
+			Link:          "<https://api.lucid.co/users?pageToken=prev>; rel=\"prev\", <https://api.lucid.co/users?pageToken=next>; rel=\"next\"",
+			ExpectedToken: "next",
+		},
+		{
+			Name:          "unquoted relation",
+			Link:          "<https://api.lucid.co/users?pageToken=next>; rel=next",
+			ExpectedToken: "next",
+		},
+		{
+			Name:          "relation list",
+			Link:          "<https://api.lucid.co/users?pageToken=next>; rel=\"last NEXT\"",
+			ExpectedToken: "next",
+		},
+		{
+			Name:          "comma in the url and the parameters",
+			Link:          "<https://api.lucid.co/users?ids=1,2&pageToken=next>; title=\"a, b; c\"; rel=\"next\"",
+			ExpectedToken: "ids=1%2C2&pageToken=next",
+		},
+		{
+			Name:          "only a previous link",
+			Link:          "<https://api.lucid.co/users?pageToken=prev>; rel=\"prev\"",
+			ExpectedToken: "",
+		},
+		{
+			Name:          "next link without a token",
+			Link:          "<https://api.lucid.co/users>; rel=\"next\"",
+			ExpectedError: true,
+		},
+		{
+			Name:          "malformed link",
+			Link:          "https://api.lucid.co/users?pageToken=next; rel=\"next\"",
+			ExpectedError: true,
+		},
 	}
 
 	for _, s := range cases {
 		t.Run(s.Name, func(t *testing.T) {
 			token, err := extractPageToken(s.Link)
+			if s.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+
 			require.NoError(t, err)
 			require.Equal(t, s.ExpectedToken, token)
 		})
 	}
 }
+
+func TestAddPageToken(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Url           string
+		PageToken     string
+		ExpectedQuery string
+	}{
+		{
+			Name:          "no token",
+			Url:           "https://api.lucid.co/users?pageSize=10",
+			PageToken:     "",
+			ExpectedQuery: "pageSize=10",
+		},
+		{
+			Name:          "token",
+			Url:           "https://api.lucid.co/users?pageSize=10",
+			PageToken:     "eyJvIjoiMSJ9",
+			ExpectedQuery: "pageSize=10&pageToken=eyJvIjoiMSJ9",
+		},
+		{
+			Name:          "padded token",
+			Url:           "https://api.lucid.co/users",
+			PageToken:     "eyJvIjoxfQ==",
+			ExpectedQuery: "pageToken=eyJvIjoxfQ%3D%3D",
+		},
+		{
+			Name:          "token with other parameters",
+			Url:           "https://api.lucid.co/users?pageSize=10",
+			PageToken:     "pageSize=5&pageToken=next&since=2024",
+			ExpectedQuery: "pageSize=5&pageToken=next&since=2024",
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.Name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, s.Url, nil)
+			require.NoError(t, err)
+
+			addPageToken(req, s.PageToken)
+			require.Equal(t, s.ExpectedQuery, req.URL.RawQuery)
+		})
+	}
+}
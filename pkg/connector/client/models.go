@@ -24,6 +24,11 @@ type Folder struct {
 	Trashed *time.Time `json:"trashed"`
 }
 
+const (
+	FolderTypeFolder = "folder"
+	FolderTypeTeam   = "team"
+)
+
 type FolderContent struct {
 	Id       interface{} `json:"id"`
 	Type     string      `json:"type"`
@@ -33,7 +38,19 @@ type FolderContent struct {
 }
 
 func (f *FolderContent) ID() string {
-	switch v := f.Id.(type) {
+	return idString(f.Id)
+}
+
+// IsFolder returns whether the content is a folder, team folders included.
+func (f *FolderContent) IsFolder() bool {
+	return f.Type == FolderTypeFolder || f.Type == FolderTypeTeam
+}
+
+// idString normalizes the ids returned by Lucid, which can be either numbers or strings depending on the endpoint.
+func idString(id interface{}) string {
+	switch v := id.(type) {
+	case nil:
+		return ""
 	case int:
 		return strconv.Itoa(v)
 	case string:
@@ -43,7 +60,7 @@ func (f *FolderContent) ID() string {
 	case float64:
 		return fmt.Sprintf("%0.f", v)
 	default:
-		panic(fmt.Sprintf("unexpected type for id: %T", f.Id))
+		panic(fmt.Sprintf("unexpected type for id: %T", id))
 	}
 }
 
@@ -64,6 +81,46 @@ type AccountDocument struct {
 	Trashed      *time.Time `json:"trashed"`
 }
 
+const (
+	OwnerTypeUser = "user"
+	OwnerTypeTeam = "team"
+)
+
+type DocumentOwner struct {
+	Id   interface{} `json:"id"`
+	Type string      `json:"type"`
+	Name string      `json:"name"`
+}
+
+func (o *DocumentOwner) ID() string {
+	return idString(o.Id)
+}
+
+type Document struct {
+	DocumentId   string        `json:"documentId"`
+	Title        string        `json:"title"`
+	EditUrl      string        `json:"editUrl"`
+	ViewUrl      string        `json:"viewUrl"`
+	Version      int           `json:"version"`
+	PageCount    int           `json:"pageCount"`
+	CanEdit      bool          `json:"canEdit"`
+	Created      time.Time     `json:"created"`
+	CreatorId    int           `json:"creatorId"`
+	LastModified time.Time     `json:"lastModified"`
+	Product      string        `json:"product"`
+	Status       string        `json:"status"`
+	Parent       int           `json:"parent"`
+	Owner        DocumentOwner `json:"owner"`
+	Trashed      *time.Time    `json:"trashed"`
+}
+
+type Team struct {
+	TeamId   int        `json:"teamId"`
+	Name     string     `json:"name"`
+	Created  time.Time  `json:"created"`
+	Archived *time.Time `json:"archived"`
+}
+
 type DocumentUserCollaboration struct {
 	DocumentId string    `json:"documentId"`
 	UserId     int       `json:"userId"`
@@ -78,6 +135,13 @@ type FolderUserCollaboration struct {
 	Created  time.Time `json:"created"`
 }
 
+type DocumentGroupCollaboration struct {
+	DocumentId string    `json:"documentId"`
+	GroupId    int       `json:"groupId"`
+	Role       string    `json:"role"`
+	Created    time.Time `json:"created"`
+}
+
 type FolderGroupCollaborator struct {
 	FolderId int       `json:"folderId"`
 	GroupId  int       `json:"groupId"`
@@ -85,18 +149,60 @@ type FolderGroupCollaborator struct {
 	Created  time.Time `json:"created"`
 }
 
+type ShareLinkSecurity struct {
+	RestrictToAccount bool       `json:"restrictToAccount"`
+	Expires           *time.Time `json:"expires,omitempty"`
+	Passcode          string     `json:"passcode,omitempty"`
+	AllowAnonymous    bool       `json:"allowAnonymous"`
+}
+
 type DocumentShareLink struct {
-	ShareLinkId  string `json:"shareLinkId"`
-	DocumentId   string `json:"documentId"`
-	Role         string `json:"role"`
-	LinkSecurity struct {
-		RestrictToAccount bool      `json:"restrictToAccount"`
-		Expires           time.Time `json:"expires"`
-		Passcode          string    `json:"passcode"`
-		AllowAnonymous    bool      `json:"allowAnonymous"`
-	} `json:"linkSecurity"`
-	Created      time.Time `json:"created"`
-	CreatedBy    int       `json:"createdBy"`
-	LastModified time.Time `json:"lastModified"`
-	AcceptUrl    string    `json:"acceptUrl"`
+	ShareLinkId  string            `json:"shareLinkId"`
+	DocumentId   string            `json:"documentId"`
+	Role         string            `json:"role"`
+	LinkSecurity ShareLinkSecurity `json:"linkSecurity"`
+	Created      time.Time         `json:"created"`
+	CreatedBy    int               `json:"createdBy"`
+	LastModified time.Time         `json:"lastModified"`
+	AcceptUrl    string            `json:"acceptUrl"`
+}
+
+const (
+	AuditLogEventDocumentShareCreated = "documentShareCreated"
+	AuditLogEventDocumentShareUpdated = "documentShareUpdated"
+	AuditLogEventDocumentShareDeleted = "documentShareDeleted"
+	AuditLogEventFolderShareCreated   = "folderShareCreated"
+	AuditLogEventFolderShareUpdated   = "folderShareUpdated"
+	AuditLogEventFolderShareDeleted   = "folderShareDeleted"
+	AuditLogEventUserCreated          = "userCreated"
+	AuditLogEventUserDeactivated      = "userDeactivated"
+	AuditLogEventDocumentCreated      = "documentCreated"
+	AuditLogEventDocumentTrashed      = "documentTrashed"
+)
+
+type AuditLogUser struct {
+	UserId int    `json:"userId"`
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+}
+
+type AuditLogTarget struct {
+	Id      interface{} `json:"id"`
+	Type    string      `json:"type"`
+	Name    string      `json:"name"`
+	Product string      `json:"product"`
+}
+
+func (t *AuditLogTarget) ID() string {
+	return idString(t.Id)
+}
+
+type AuditLogEvent struct {
+	EventId      string         `json:"eventId"`
+	EventType    string         `json:"eventType"`
+	Timestamp    time.Time      `json:"timestamp"`
+	Actor        AuditLogUser   `json:"actor"`
+	Target       AuditLogTarget `json:"target"`
+	Role         string         `json:"role"`
+	Collaborator *AuditLogUser  `json:"collaborator"`
 }
@@ -13,3 +13,9 @@ func TestIdParse(t *testing.T) {
 
 	require.Equal(t, structTest.ID(), "397240323")
 }
+
+func TestDocumentOwnerIdParse(t *testing.T) {
+	require.Equal(t, "12345", (&DocumentOwner{Id: float64(12345)}).ID())
+	require.Equal(t, "abc", (&DocumentOwner{Id: "abc"}).ID())
+	require.Equal(t, "", (&DocumentOwner{}).ID())
+}
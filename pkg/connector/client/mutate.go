@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 )
 
 func (c *LucidchartClient) UpsertFolderUserCollaborator(ctx context.Context, folderId, userId string, role string) (*FolderUserCollaboration, error) {
@@ -17,7 +18,7 @@ func (c *LucidchartClient) UpsertFolderUserCollaborator(ctx context.Context, fol
 		Role: role,
 	}
 
-	req, err := c.newRequest(ctx, LucidchartApiUrl, http.MethodPut, path, body, LucidAuthTypeApiKey)
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodPut, path, body, LucidAuthTypeApiKey)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +33,7 @@ func (c *LucidchartClient) UpsertFolderUserCollaborator(ctx context.Context, fol
 func (c *LucidchartClient) DeleteFolderUserCollaborator(ctx context.Context, folderId, userId string) error {
 	path := fmt.Sprintf(DeleteFolderUserCollaboratorPath, folderId, userId)
 
-	req, err := c.newRequest(ctx, LucidchartApiUrl, http.MethodDelete, path, nil, LucidAuthTypeApiKey)
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodDelete, path, nil, LucidAuthTypeApiKey)
 	if err != nil {
 		return err
 	}
@@ -44,6 +45,29 @@ func (c *LucidchartClient) DeleteFolderUserCollaborator(ctx context.Context, fol
 	return nil
 }
 
+func (c *LucidchartClient) UpsertFolderGroupCollaborator(ctx context.Context, folderId, groupId string, role string) (*FolderGroupCollaborator, error) {
+	var response FolderGroupCollaborator
+
+	path := fmt.Sprintf(UpsertFolderGroupCollaboratorPath, folderId, groupId)
+
+	body := struct {
+		Role string `json:"role"`
+	}{
+		Role: role,
+	}
+
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodPut, path, body, LucidAuthTypeApiKey)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.doRequest(ctx, req, &response, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
 func (c *LucidchartClient) UpsertDocumentUserCollaborator(ctx context.Context, documentId, userId string, role string) (*DocumentUserCollaboration, error) {
 	var response DocumentUserCollaboration
 
@@ -55,7 +79,7 @@ func (c *LucidchartClient) UpsertDocumentUserCollaborator(ctx context.Context, d
 		Role: role,
 	}
 
-	req, err := c.newRequest(ctx, LucidchartApiUrl, http.MethodPut, path, body, LucidAuthTypeApiKey)
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodPut, path, body, LucidAuthTypeApiKey)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +94,7 @@ func (c *LucidchartClient) UpsertDocumentUserCollaborator(ctx context.Context, d
 func (c *LucidchartClient) DeleteDocumentUserCollaborator(ctx context.Context, documentId, userId string) error {
 	path := fmt.Sprintf(DeleteDocumentUserCollaboratorPath, documentId, userId)
 
-	req, err := c.newRequest(ctx, LucidchartApiUrl, http.MethodDelete, path, nil, LucidAuthTypeApiKey)
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodDelete, path, nil, LucidAuthTypeApiKey)
 	if err != nil {
 		return err
 	}
@@ -81,3 +105,311 @@ func (c *LucidchartClient) DeleteDocumentUserCollaborator(ctx context.Context, d
 
 	return nil
 }
+
+// TransferDocumentOwnership makes the given user the owner of the document.
+func (c *LucidchartClient) TransferDocumentOwnership(ctx context.Context, documentId, userId string) (*Document, error) {
+	var response Document
+
+	path := fmt.Sprintf(TransferDocumentOwnershipPath, documentId)
+
+	body := struct {
+		UserId string `json:"userId"`
+	}{
+		UserId: userId,
+	}
+
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodPut, path, body, LucidAuthTypeApiKey)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.doRequest(ctx, req, &response, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (c *LucidchartClient) UpsertDocumentGroupCollaborator(ctx context.Context, documentId, groupId string, role string) (*DocumentGroupCollaboration, error) {
+	var response DocumentGroupCollaboration
+
+	path := fmt.Sprintf(UpsertDocumentGroupCollaboratorPath, documentId, groupId)
+
+	body := struct {
+		Role string `json:"role"`
+	}{
+		Role: role,
+	}
+
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodPut, path, body, LucidAuthTypeApiKey)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.doRequest(ctx, req, &response, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// CreateDocument creates a document in the parent folder, or in the root folder when parentId is
+// rootFolderId. The document is a copy of the template when templateId is set, and a blank document of
+// the product otherwise.
+func (c *LucidchartClient) CreateDocument(ctx context.Context, title, product, parentId, templateId string) (*Document, error) {
+	var response Document
+
+	parent, err := folderParent(parentId)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{
+		"title": title,
+	}
+	if templateId != "" {
+		body["template"] = templateId
+	} else {
+		body["product"] = product
+	}
+	if parent != nil {
+		body["parent"] = parent
+	}
+
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodPost, CreateDocumentPath, body, LucidAuthTypeApiKey)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.doRequest(ctx, req, &response, false)
+	if err != nil {
+		return nil, err
+	}
+
+	c.folderContentCache.drop(parentId)
+
+	return &response, nil
+}
+
+func (c *LucidchartClient) CreateDocumentShareLink(ctx context.Context, documentId, role string, security ShareLinkSecurity) (*DocumentShareLink, error) {
+	var response DocumentShareLink
+
+	path := fmt.Sprintf(CreateDocumentShareLinkPath, documentId)
+
+	body := struct {
+		Role         string            `json:"role"`
+		LinkSecurity ShareLinkSecurity `json:"linkSecurity"`
+	}{
+		Role:         role,
+		LinkSecurity: security,
+	}
+
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodPost, path, body, LucidAuthTypeApiKey)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.doRequest(ctx, req, &response, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (c *LucidchartClient) UpdateDocumentShareLink(ctx context.Context, documentId, shareLinkId, role string, security ShareLinkSecurity) (*DocumentShareLink, error) {
+	var response DocumentShareLink
+
+	path := fmt.Sprintf(UpdateDocumentShareLinkPath, documentId, shareLinkId)
+
+	body := struct {
+		Role         string            `json:"role,omitempty"`
+		LinkSecurity ShareLinkSecurity `json:"linkSecurity"`
+	}{
+		Role:         role,
+		LinkSecurity: security,
+	}
+
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodPatch, path, body, LucidAuthTypeApiKey)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.doRequest(ctx, req, &response, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (c *LucidchartClient) DeleteDocumentShareLink(ctx context.Context, documentId, shareLinkId string) error {
+	path := fmt.Sprintf(DeleteDocumentShareLinkPath, documentId, shareLinkId)
+
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodDelete, path, nil, LucidAuthTypeApiKey)
+	if err != nil {
+		return err
+	}
+	_, err = c.doRequest(ctx, req, nil, false)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateFolder creates a folder in the parent folder, or in the root folder when parentId is rootFolderId.
+func (c *LucidchartClient) CreateFolder(ctx context.Context, name, parentId string) (*Folder, error) {
+	var response Folder
+
+	parent, err := folderParent(parentId)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{
+		"name": name,
+		"type": FolderTypeFolder,
+	}
+	if parent != nil {
+		body["parent"] = parent
+	}
+
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodPost, CreateFolderPath, body, LucidAuthTypeApiKey)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.doRequest(ctx, req, &response, false)
+	if err != nil {
+		return nil, err
+	}
+
+	c.folderContentCache.drop(parentId)
+
+	return &response, nil
+}
+
+// UpdateFolder renames the folder when name is set, and moves it when parentId is set. Moving a folder
+// to rootFolderId moves it to the root folder.
+func (c *LucidchartClient) UpdateFolder(ctx context.Context, folderId string, name, parentId *string) (*Folder, error) {
+	var response Folder
+
+	path := fmt.Sprintf(UpdateFolderPath, folderId)
+
+	body := map[string]interface{}{}
+	if name != nil {
+		body["name"] = *name
+	}
+	if parentId != nil {
+		parent, err := folderParent(*parentId)
+		if err != nil {
+			return nil, err
+		}
+
+		// A null parent moves the folder to the root folder.
+		body["parent"] = parent
+	}
+
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodPatch, path, body, LucidAuthTypeApiKey)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.doRequest(ctx, req, &response, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// The pages of the previous parent are dropped as they list the folder.
+	if parentId != nil {
+		c.folderContentCache.drop(folderId, *parentId)
+	} else {
+		c.folderContentCache.drop(folderId)
+	}
+
+	return &response, nil
+}
+
+// folderParent returns the parent of a folder as sent to the folders API, which takes numeric ids and
+// nil for the root folder.
+func folderParent(parentId string) (*int, error) {
+	if parentId == rootFolderId {
+		return nil, nil
+	}
+
+	id, err := strconv.Atoi(parentId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid folder id %s: %w", parentId, err)
+	}
+
+	return &id, nil
+}
+
+// TrashFolder moves the folder, with its content, to the trash.
+func (c *LucidchartClient) TrashFolder(ctx context.Context, folderId string) error {
+	return c.doMutation(ctx, http.MethodPost, fmt.Sprintf(TrashFolderPath, folderId), folderId)
+}
+
+// RestoreFolder moves the folder, with its content, out of the trash.
+func (c *LucidchartClient) RestoreFolder(ctx context.Context, folderId string) error {
+	err := c.doAction(ctx, http.MethodPost, fmt.Sprintf(RestoreFolderPath, folderId))
+	if err != nil {
+		return err
+	}
+
+	// The parent isn't known, and its cached pages may not list the restored folder.
+	c.folderContentCache.reset()
+
+	return nil
+}
+
+// DeleteFolder permanently deletes a trashed folder.
+func (c *LucidchartClient) DeleteFolder(ctx context.Context, folderId string) error {
+	return c.doMutation(ctx, http.MethodDelete, fmt.Sprintf(DeleteFolderPath, folderId), folderId)
+}
+
+// TrashDocument moves the document to the trash.
+func (c *LucidchartClient) TrashDocument(ctx context.Context, documentId string) error {
+	return c.doMutation(ctx, http.MethodPost, fmt.Sprintf(TrashDocumentPath, documentId), documentId)
+}
+
+// RestoreDocument moves the document out of the trash.
+func (c *LucidchartClient) RestoreDocument(ctx context.Context, documentId string) error {
+	err := c.doAction(ctx, http.MethodPost, fmt.Sprintf(RestoreDocumentPath, documentId))
+	if err != nil {
+		return err
+	}
+
+	// The parent isn't known, and its cached pages may not list the restored document.
+	c.folderContentCache.reset()
+
+	return nil
+}
+
+// DeleteDocument permanently deletes a trashed document.
+func (c *LucidchartClient) DeleteDocument(ctx context.Context, documentId string) error {
+	return c.doMutation(ctx, http.MethodDelete, fmt.Sprintf(DeleteDocumentPath, documentId), documentId)
+}
+
+// doAction sends a request without body, for the endpoints that don't return anything.
+func (c *LucidchartClient) doAction(ctx context.Context, method, path string) error {
+	req, err := c.newRequest(ctx, c.baseUrl, method, path, nil, LucidAuthTypeApiKey)
+	if err != nil {
+		return err
+	}
+	_, err = c.doRequest(ctx, req, nil, false)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// doMutation sends a request without body changing a folder or a document, and drops the cached pages of
+// the folders it changed.
+func (c *LucidchartClient) doMutation(ctx context.Context, method, path, id string) error {
+	err := c.doAction(ctx, method, path)
+	if err != nil {
+		return err
+	}
+
+	c.folderContentCache.drop(id)
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFolderMutations(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       func(ctx context.Context, c *LucidchartClient) (*Folder, error)
+		wantMethod string
+		wantPath   string
+		wantBody   map[string]interface{}
+	}{
+		{
+			name: "create in root folder",
+			call: func(ctx context.Context, c *LucidchartClient) (*Folder, error) {
+				return c.CreateFolder(ctx, "Project", "root")
+			},
+			wantMethod: http.MethodPost,
+			wantPath:   "/folders",
+			wantBody:   map[string]interface{}{"name": "Project", "type": "folder"},
+		},
+		{
+			name: "create in folder",
+			call: func(ctx context.Context, c *LucidchartClient) (*Folder, error) {
+				return c.CreateFolder(ctx, "Project", "12")
+			},
+			wantMethod: http.MethodPost,
+			wantPath:   "/folders",
+			wantBody:   map[string]interface{}{"name": "Project", "type": "folder", "parent": float64(12)},
+		},
+		{
+			name: "rename",
+			call: func(ctx context.Context, c *LucidchartClient) (*Folder, error) {
+				name := "Renamed"
+				return c.UpdateFolder(ctx, "34", &name, nil)
+			},
+			wantMethod: http.MethodPatch,
+			wantPath:   "/folders/34",
+			wantBody:   map[string]interface{}{"name": "Renamed"},
+		},
+		{
+			name: "move to root folder",
+			call: func(ctx context.Context, c *LucidchartClient) (*Folder, error) {
+				parentId := "root"
+				return c.UpdateFolder(ctx, "34", nil, &parentId)
+			},
+			wantMethod: http.MethodPatch,
+			wantPath:   "/folders/34",
+			wantBody:   map[string]interface{}{"parent": nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, path string
+			var body map[string]interface{}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method, path = r.Method, r.URL.Path
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				if err := json.NewEncoder(w).Encode(Folder{Id: 34, Type: "folder", Name: "Project"}); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+			}))
+			defer server.Close()
+
+			folder, err := tt.call(context.Background(), newTestClient(t, server))
+			require.NoError(t, err)
+			require.Equal(t, 34, folder.Id)
+			require.Equal(t, tt.wantMethod, method)
+			require.Equal(t, tt.wantPath, path)
+			require.Equal(t, tt.wantBody, body)
+		})
+	}
+}
+
+func TestCreateDocument(t *testing.T) {
+	tests := []struct {
+		name       string
+		parentId   string
+		templateId string
+		wantBody   map[string]interface{}
+	}{
+		{
+			name:     "blank document in root folder",
+			parentId: "root",
+			wantBody: map[string]interface{}{"title": "Review", "product": "lucidchart"},
+		},
+		{
+			name:       "copy of template in folder",
+			parentId:   "12",
+			templateId: "template",
+			wantBody:   map[string]interface{}{"title": "Review", "template": "template", "parent": float64(12)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]interface{}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/documents" {
+					http.NotFound(w, r)
+					return
+				}
+
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				if err := json.NewEncoder(w).Encode(Document{DocumentId: "doc", Title: "Review"}); err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				}
+			}))
+			defer server.Close()
+
+			document, err := newTestClient(t, server).CreateDocument(context.Background(), "Review", ProductLucidchart, tt.parentId, tt.templateId)
+			require.NoError(t, err)
+			require.Equal(t, "doc", document.DocumentId)
+			require.Equal(t, tt.wantBody, body)
+		})
+	}
+}
@@ -35,6 +35,12 @@ type LucidChartOAuth2Options struct {
 
 	// RefreshToken is the last refresh token to use to get a new access token.
 	RefreshToken string
+
+	// BaseUrl is the URL of the Lucid API serving the token endpoint, LucidchartApiUrl when empty.
+	BaseUrl ClientUrl
+
+	// transport replaces the transport of the token requests, to record or replay them with a cassette.
+	transport http.RoundTripper
 }
 
 type LucidChartOAuth2 struct {
@@ -51,6 +57,10 @@ func NewLucidChartOAuth2(ctx context.Context, opts *LucidChartOAuth2Options) (*L
 		return nil, err
 	}
 
+	if opts.transport != nil {
+		httpClient.Transport = opts.transport
+	}
+
 	uhttpClient, err := uhttp.NewBaseHttpClientWithContext(ctx, httpClient)
 	if err != nil {
 		return nil, err
@@ -131,7 +141,7 @@ func (c *LucidChartOAuth2) GetToken(ctx context.Context) (*GetTokenResponse, err
 		RedirectURI:  c.opts.RedirectUrl,
 	}
 
-	endPoint, err := url.Parse("https://api.lucid.co/oauth2/token")
+	endPoint, err := c.tokenUrl()
 	if err != nil {
 		return nil, err
 	}
@@ -158,22 +168,40 @@ func (c *LucidChartOAuth2) GetToken(ctx context.Context) (*GetTokenResponse, err
 
 	c.token = &respVar
 
-	l.Debug("Token received", zap.Any("token", c.token))
+	l.Debug("Token received", zap.Int64("expires", c.token.Expires))
 
 	return &respVar, nil
 }
 
+// renewToken refreshes the token after Lucid rejected the access token, unless another request already
+// renewed it.
+func (c *LucidChartOAuth2) renewToken(ctx context.Context, rejected string) (*GetTokenResponse, error) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	if c.token != nil && c.token.AccessToken != rejected {
+		return c.token, nil
+	}
+
+	token, err := c.refreshToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.token = token
+
+	return c.token, nil
+}
+
 func (c *LucidChartOAuth2) refreshToken(ctx context.Context) (*GetTokenResponse, error) {
 	l := ctxzap.Extract(ctx)
 
 	l.Info("Getting refresh token")
 
-	var resfreshToken string
-
-	if c.token != nil {
-		resfreshToken = c.token.AccessToken
-	} else {
-		resfreshToken = c.opts.RefreshToken
+	// Lucid may rotate the refresh token with each new token.
+	resfreshToken := c.opts.RefreshToken
+	if c.token != nil && c.token.RefreshToken != "" {
+		resfreshToken = c.token.RefreshToken
 	}
 
 	if resfreshToken == "" {
@@ -194,7 +222,7 @@ func (c *LucidChartOAuth2) refreshToken(ctx context.Context) (*GetTokenResponse,
 		GrantType:    "refresh_token",
 	}
 
-	endPoint, err := url.Parse("https://api.lucid.co/oauth2/token")
+	endPoint, err := c.tokenUrl()
 	if err != nil {
 		return nil, err
 	}
@@ -219,11 +247,25 @@ func (c *LucidChartOAuth2) refreshToken(ctx context.Context) (*GetTokenResponse,
 
 	defer resp.Body.Close()
 
-	l.Debug("Refresh token received", zap.Any("token", respVar))
+	l.Debug("Refresh token received", zap.Int64("expires", respVar.Expires))
 
 	return &respVar, nil
 }
 
+func (c *LucidChartOAuth2) tokenUrl() (*url.URL, error) {
+	baseUrl := c.opts.BaseUrl
+	if baseUrl == "" {
+		baseUrl = LucidchartApiUrl
+	}
+
+	endPoint, err := url.Parse(string(baseUrl))
+	if err != nil {
+		return nil, err
+	}
+
+	return endPoint.JoinPath(TokenPath), nil
+}
+
 func parseLucidChartResponseError(resp *http.Response, err error) error {
 	if resp != nil && resp.StatusCode == http.StatusBadRequest {
 		defer resp.Body.Close()
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// authServer serves every endpoint with an empty page, and records the authorization of the requests by
+// path. Tokens are issued as token-1, token-2, and so on, with the refresh tokens refresh-1, refresh-2, and
+// so on, and the rejected tokens get a 401.
+type authServer struct {
+	*httptest.Server
+
+	mutex          sync.Mutex
+	rejected       map[string]bool
+	tokens         int
+	refreshTokens  []string
+	authorizations map[string][]string
+}
+
+func newAuthServer(t *testing.T, rejected ...string) *authServer {
+	t.Helper()
+
+	s := &authServer{rejected: map[string]bool{}, authorizations: map[string][]string{}}
+	for _, token := range rejected {
+		s.rejected[token] = true
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == TokenPath {
+			var body struct {
+				RefreshToken string `json:"refresh_token"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			s.refreshTokens = append(s.refreshTokens, body.RefreshToken)
+
+			s.tokens++
+			require.NoError(t, json.NewEncoder(w).Encode(GetTokenResponse{
+				AccessToken:  fmt.Sprintf("token-%d", s.tokens),
+				RefreshToken: fmt.Sprintf("refresh-%d", s.tokens),
+				Expires:      time.Now().Add(time.Hour).UnixMilli(),
+			}))
+			return
+		}
+
+		authorization := r.Header.Get("Authorization")
+		s.authorizations[r.URL.Path] = append(s.authorizations[r.URL.Path], authorization)
+
+		if s.rejected[strings.TrimPrefix(authorization, "Bearer ")] {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":"unauthorized"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// requests returns the authorizations of the requests to a path.
+func (s *authServer) requests(path string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string(nil), s.authorizations[path]...)
+}
+
+func (s *authServer) paths() map[string][]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rv := map[string][]string{}
+	for path, values := range s.authorizations {
+		rv[path] = append([]string(nil), values...)
+	}
+
+	return rv
+}
+
+// usedRefreshTokens returns the refresh tokens sent to the token endpoint.
+func (s *authServer) usedRefreshTokens() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string(nil), s.refreshTokens...)
+}
+
+func newTestOAuth2Client(ctx context.Context, t *testing.T, server *authServer, apiKey string) *LucidchartClient {
+	t.Helper()
+
+	c, err := NewLucidchartClient(
+		ctx,
+		apiKey,
+		&LucidChartOAuth2Options{ClientID: "client-id", ClientSecret: "client-secret", RefreshToken: "refresh-token"},
+		WithBaseUrl(ClientUrl(server.URL)),
+	)
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	server := newAuthServer(t)
+	c := newTestOAuth2Client(ctx, t, server, "api-key")
+
+	token, err := c.lucidCharToken.GetToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token.AccessToken)
+
+	// The expired token is refreshed with the refresh token Lucid rotated, not with the access token.
+	token.Expires = time.Now().Add(-time.Minute).UnixMilli()
+
+	token, err = c.lucidCharToken.GetToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "token-2", token.AccessToken)
+
+	require.Equal(t, []string{"refresh-token", "refresh-1"}, server.usedRefreshTokens())
+}
+
+func TestRetryRejectedToken(t *testing.T) {
+	ctx := context.Background()
+	server := newAuthServer(t, "token-1", "api-key")
+	c := newTestOAuth2Client(ctx, t, server, "api-key")
+
+	_, _, err := c.ListUser(ctx, "")
+	require.NoError(t, err)
+
+	// The rejected token is renewed once, and the renewed one is kept.
+	_, _, err = c.ListTeams(ctx, "")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, server.requests(GetUsersPath))
+	require.Equal(t, []string{"Bearer token-2"}, server.requests(GetTeamsPath))
+
+	// A rejected API key isn't replaced with a token.
+	_, _, err = c.FolderContent(ctx, "1", "")
+	require.Error(t, err)
+	require.Equal(t, []string{"Bearer api-key"}, server.requests("/folders/1/contents"))
+	require.Len(t, server.usedRefreshTokens(), 2)
+}
+
+func TestTokensNotLogged(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := ctxzap.ToContext(context.Background(), zap.New(core))
+
+	server := newAuthServer(t, "token-1")
+	c := newTestOAuth2Client(ctx, t, server, "api-key")
+
+	_, _, err := c.ListUser(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, server.usedRefreshTokens(), 2)
+
+	for _, entry := range logs.All() {
+		logged := fmt.Sprint(entry.Message, entry.ContextMap())
+		for _, secret := range []string{"token-1", "token-2", "refresh-1", "refresh-2", "refresh-token"} {
+			require.NotContains(t, logged, secret)
+		}
+	}
+}
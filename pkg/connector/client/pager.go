@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// maxPages is the number of pages EachPage lists before giving up. A list endpoint returning its own page
+// token as the next one is already stopped by page, this only bounds one returning new tokens forever.
+// It is far more pages than the collaborators of any folder or document fill.
+const maxPages = 10000
+
+// MaxPageSize is the largest number of items Lucid returns in a page of its list endpoints.
+const MaxPageSize = 200
+
+// WithPageSize sets the number of items requested in each page of the list endpoints, Lucid's default
+// page size being used when 0. It can't be more than MaxPageSize.
+func WithPageSize(pageSize int) ClientOption {
+	return func(c *LucidchartClient) {
+		c.pageSize = pageSize
+	}
+}
+
+// pager requests the pages of a list endpoint, decoding their items as T.
+type pager[T any] struct {
+	client   *LucidchartClient
+	path     string
+	authType LucidAuthType
+	query    url.Values
+}
+
+func newPager[T any](c *LucidchartClient, path string, authType LucidAuthType) *pager[T] {
+	return &pager[T]{
+		client:   c,
+		path:     path,
+		authType: authType,
+		query:    url.Values{},
+	}
+}
+
+// withQuery sets a query parameter on the requests of every page.
+func (p *pager[T]) withQuery(key, value string) *pager[T] {
+	p.query.Set(key, value)
+
+	return p
+}
+
+// page requests the page of the token, the first page when empty, and returns its items and the token of
+// the next page, empty on the last page.
+func (p *pager[T]) page(ctx context.Context, pageToken string) ([]T, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	req, err := p.client.newRequest(ctx, p.client.baseUrl, http.MethodGet, p.path, nil, p.authType)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := req.URL.Query()
+	if p.client.pageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(p.client.pageSize))
+	}
+	for key, values := range p.query {
+		query[key] = values
+	}
+	req.URL.RawQuery = query.Encode()
+
+	addPageToken(req, pageToken)
+
+	var response []T
+
+	nextToken, err := p.client.doRequest(ctx, req, &response, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Following the same token again would never end.
+	if nextToken != "" && nextToken == pageToken {
+		return nil, "", fmt.Errorf("baton-lucidchart: %s returned its own page token as the next one", p.path)
+	}
+
+	return response, nextToken, nil
+}
+
+// EachPage calls fn with the items of every page returned by list, starting from the first one, until fn
+// returns false or there are no more pages. It gives up with an error after maxPages pages.
+func EachPage[T any](
+	ctx context.Context,
+	list func(ctx context.Context, pageToken string) ([]T, string, error),
+	fn func(items []T) (bool, error),
+) error {
+	return eachPage(ctx, list, fn, maxPages)
+}
+
+// eachPage is EachPage giving up after limit pages.
+func eachPage[T any](
+	ctx context.Context,
+	list func(ctx context.Context, pageToken string) ([]T, string, error),
+	fn func(items []T) (bool, error),
+	limit int,
+) error {
+	pageToken := ""
+	for pages := 0; ; pages++ {
+		if pages == limit {
+			return fmt.Errorf("baton-lucidchart: stopped listing after %d pages", limit)
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, nextToken, err := list(ctx, pageToken)
+		if err != nil {
+			return err
+		}
+
+		more, err := fn(items)
+		if err != nil {
+			return err
+		}
+
+		if !more || nextToken == "" {
+			return nil
+		}
+
+		pageToken = nextToken
+	}
+}
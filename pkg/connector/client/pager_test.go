@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newPagedServer serves the numbers from 0 to count, in pages of the requested size, 2 by default.
+func newPagedServer(t *testing.T, count int, queries chan<- url.Values) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if queries != nil {
+			queries <- query
+		}
+
+		pageSize := 2
+		if size := query.Get("pageSize"); size != "" {
+			pageSize, _ = strconv.Atoi(size)
+		}
+
+		start, _ := strconv.Atoi(query.Get("pageToken"))
+		end := start + pageSize
+		if end < count {
+			w.Header().Set("Link", fmt.Sprintf("<http://%s%s?pageToken=%d>; rel=\"next\"", r.Host, r.URL.Path, end))
+		} else {
+			end = count
+		}
+
+		page := []int{}
+		for i := start; i < end; i++ {
+			page = append(page, i)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(page))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestPager(t *testing.T) {
+	tests := []struct {
+		name      string
+		pageSize  int
+		query     map[string]string
+		pageToken string
+		wantQuery url.Values
+		wantItems []int
+		wantNext  string
+	}{
+		{
+			name:      "default page size",
+			wantQuery: url.Values{},
+			wantItems: []int{0, 1},
+			wantNext:  "2",
+		},
+		{
+			name:      "client page size",
+			pageSize:  3,
+			wantQuery: url.Values{"pageSize": {"3"}},
+			wantItems: []int{0, 1, 2},
+			wantNext:  "3",
+		},
+		{
+			name:      "endpoint page size",
+			pageSize:  3,
+			query:     map[string]string{"pageSize": "4", "from": "2024"},
+			wantQuery: url.Values{"pageSize": {"4"}, "from": {"2024"}},
+			wantItems: []int{0, 1, 2, 3},
+			wantNext:  "4",
+		},
+		{
+			name:      "last page",
+			pageToken: "4",
+			wantQuery: url.Values{"pageToken": {"4"}},
+			wantItems: []int{4},
+			wantNext:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := make(chan url.Values, 1)
+			c := newTestClient(t, newPagedServer(t, 5, queries))
+			c.pageSize = tt.pageSize
+
+			p := newPager[int](c, "/numbers", LucidAuthTypeApiKey)
+			for key, value := range tt.query {
+				p.withQuery(key, value)
+			}
+
+			items, next, err := p.page(context.Background(), tt.pageToken)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantItems, items)
+			require.Equal(t, tt.wantNext, next)
+			require.Equal(t, tt.wantQuery, <-queries)
+		})
+	}
+}
+
+func TestPagerErrors(t *testing.T) {
+	t.Run("canceled context", func(t *testing.T) {
+		c := newTestClient(t, newPagedServer(t, 5, nil))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, _, err := newPager[int](c, "/numbers", LucidAuthTypeApiKey).page(ctx, "")
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("next page is the same page", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Link", fmt.Sprintf("<http://%s%s?pageToken=loop>; rel=\"next\"", r.Host, r.URL.Path))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte("[]"))
+		}))
+		t.Cleanup(server.Close)
+
+		c := newTestClient(t, server)
+
+		_, _, err := newPager[int](c, "/numbers", LucidAuthTypeApiKey).page(context.Background(), "loop")
+		require.ErrorContains(t, err, "returned its own page token")
+	})
+}
+
+func TestEachPage(t *testing.T) {
+	list := func(c *LucidchartClient) func(ctx context.Context, pageToken string) ([]int, string, error) {
+		return newPager[int](c, "/numbers", LucidAuthTypeApiKey).page
+	}
+
+	t.Run("every page", func(t *testing.T) {
+		c := newTestClient(t, newPagedServer(t, 5, nil))
+
+		var items []int
+		err := EachPage(context.Background(), list(c), func(page []int) (bool, error) {
+			items = append(items, page...)
+			return true, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1, 2, 3, 4}, items)
+	})
+
+	t.Run("stop early", func(t *testing.T) {
+		c := newTestClient(t, newPagedServer(t, 5, nil))
+
+		pages := 0
+		err := EachPage(context.Background(), list(c), func(page []int) (bool, error) {
+			pages++
+			return false, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, pages)
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		c := newTestClient(t, newPagedServer(t, 5, nil))
+
+		failure := errors.New("failure")
+		err := EachPage(context.Background(), list(c), func(page []int) (bool, error) {
+			return true, failure
+		})
+		require.ErrorIs(t, err, failure)
+	})
+
+	t.Run("max pages", func(t *testing.T) {
+		c := newTestClient(t, newPagedServer(t, 5, nil))
+
+		err := eachPage(context.Background(), list(c), func(page []int) (bool, error) {
+			return true, nil
+		}, 2)
+		require.ErrorContains(t, err, "stopped listing after 2 pages")
+	})
+}
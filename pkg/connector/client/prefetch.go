@@ -0,0 +1,389 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// PrefetchOptions configures the prefetch of the folder tree, which lists the content of the folders ahead
+// of the syncer so that FolderContent serves most pages without waiting for Lucid.
+type PrefetchOptions struct {
+	// Concurrency is the number of folders listed at the same time. Their requests share the rate limit of
+	// the client with the syncer.
+	Concurrency int
+	// MaxPendingPages is the number of prefetched pages kept until FolderContent serves them. The prefetch
+	// waits once there are as many, defaultFolderContentCacheSize when 0.
+	MaxPendingPages int
+	// Walk returns whether the prefetch lists the content of a folder found at a depth, the folders of the
+	// root folder being at depth 1. Every folder is listed when nil. It must return false for the folders
+	// the sync skips, as their pages would be held until the end of the sync otherwise. The folder isn't
+	// listed when it fails.
+	Walk func(ctx context.Context, folder FolderContent, depth int) (bool, error)
+}
+
+// WithPrefetch enables the prefetch of the folder tree, which starts with each sync, see StartSync.
+func WithPrefetch(opts PrefetchOptions) ClientOption {
+	return func(c *LucidchartClient) {
+		if opts.Concurrency <= 0 {
+			opts.Concurrency = 1
+		}
+
+		if opts.MaxPendingPages <= 0 {
+			opts.MaxPendingPages = defaultFolderContentCacheSize
+		}
+
+		c.prefetcher = &prefetcher{
+			client: c,
+			opts:   opts,
+		}
+	}
+}
+
+// folderContentCall is a request for a page of folder content, shared by the prefetch and FolderContent.
+type folderContentCall struct {
+	done      chan struct{}
+	content   []FolderContent
+	nextToken string
+	err       error
+
+	// served is set once FolderContent returned the page, which then doesn't need to be kept.
+	served bool
+	// slot is set when the call holds a slot of the pending pages.
+	slot bool
+}
+
+type prefetchFolder struct {
+	id    string
+	depth int
+}
+
+// prefetcher runs the prefetch of each sync.
+type prefetcher struct {
+	client *LucidchartClient
+	opts   PrefetchOptions
+
+	mutex   sync.Mutex
+	current *prefetchRun
+}
+
+// restart stops the prefetch of the previous sync, dropping the pages it kept pending, and walks the tree
+// again from the root folder. The walk outlives the request starting the sync, so it runs in the context of
+// the sync, see SyncContext.
+func (p *prefetcher) restart(ctx context.Context) {
+	run := newPrefetchRun(ctx, p.client, p.opts)
+
+	p.mutex.Lock()
+	previous := p.current
+	p.current = run
+	p.mutex.Unlock()
+
+	previous.stop()
+
+	go run.run()
+}
+
+// get returns a page of a folder for FolderContent, from the prefetch of the sync when it started.
+func (p *prefetcher) get(ctx context.Context, folderId, path, pageToken string) ([]FolderContent, string, error) {
+	p.mutex.Lock()
+	run := p.current
+	p.mutex.Unlock()
+
+	if run == nil {
+		return newPager[FolderContent](p.client, path, LucidAuthTypeApiKey).page(ctx, pageToken)
+	}
+
+	return run.get(ctx, folderId, path, pageToken)
+}
+
+// prefetchRun walks the folder tree for a sync with a pool of workers. The pages it lists are kept as pending
+// until FolderContent serves them, and the pages being requested are shared, so that each page is requested once.
+type prefetchRun struct {
+	client *LucidchartClient
+	opts   PrefetchOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// slots bounds the number of pending pages.
+	slots chan struct{}
+	// done is closed once the whole tree was listed or the run was stopped.
+	done chan struct{}
+
+	mutex   sync.Mutex
+	calls   map[folderContentCacheKey]*folderContentCall
+	pending map[folderContentCacheKey]*folderContentCall
+	// served are the pages FolderContent returned, which the prefetch must not keep pending again.
+	served map[folderContentCacheKey]struct{}
+}
+
+func newPrefetchRun(ctx context.Context, c *LucidchartClient, opts PrefetchOptions) *prefetchRun {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &prefetchRun{
+		client:  c,
+		opts:    opts,
+		ctx:     ctx,
+		cancel:  cancel,
+		slots:   make(chan struct{}, opts.MaxPendingPages),
+		done:    make(chan struct{}),
+		calls:   make(map[folderContentCacheKey]*folderContentCall),
+		pending: make(map[folderContentCacheKey]*folderContentCall),
+		served:  make(map[folderContentCacheKey]struct{}),
+	}
+}
+
+// stop cancels the walk, waits for its workers and frees the pages that were never served.
+func (p *prefetchRun) stop() {
+	if p == nil {
+		return
+	}
+
+	p.cancel()
+	<-p.done
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for key := range p.pending {
+		delete(p.pending, key)
+		<-p.slots
+	}
+}
+
+func (p *prefetchRun) run() {
+	defer close(p.done)
+
+	ctx := p.ctx
+
+	l := ctxzap.Extract(ctx)
+
+	var (
+		mutex   sync.Mutex
+		cond    = sync.NewCond(&mutex)
+		queue   = []prefetchFolder{{id: rootFolderId}}
+		visited = map[string]bool{rootFolderId: true}
+		active  = 0
+		pages   = 0
+	)
+
+	// next returns the next folder to list, waiting for the active workers to find more, and false once
+	// the whole tree was listed.
+	next := func() (prefetchFolder, bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for len(queue) == 0 && active > 0 {
+			cond.Wait()
+		}
+
+		if len(queue) == 0 {
+			return prefetchFolder{}, false
+		}
+
+		folder := queue[0]
+		queue = queue[1:]
+		active++
+
+		return folder, true
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				folder, ok := next()
+				if !ok {
+					return
+				}
+
+				subfolders, listed := p.walk(ctx, folder)
+
+				mutex.Lock()
+				pages += listed
+				for _, subfolder := range subfolders {
+					if !visited[subfolder.id] {
+						visited[subfolder.id] = true
+						queue = append(queue, subfolder)
+					}
+				}
+				active--
+				cond.Broadcast()
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	l.Debug("baton-lucidchart: prefetched the folder tree", zap.Int("folders", len(visited)), zap.Int("pages", pages))
+}
+
+// walk lists every page of a folder and returns the subfolders to list next and the number of pages listed.
+// It gives up on the folder at the first error, the syncer then requesting the page itself.
+func (p *prefetchRun) walk(ctx context.Context, folder prefetchFolder) ([]prefetchFolder, int) {
+	l := ctxzap.Extract(ctx)
+
+	var subfolders []prefetchFolder
+
+	pageToken := ""
+	for pages := 0; ; {
+		content, nextToken, err := p.prefetch(ctx, folder.id, pageToken)
+		if err != nil {
+			l.Debug("baton-lucidchart: stopped prefetching a folder", zap.String("folder_id", folder.id), zap.Error(err))
+			return subfolders, pages
+		}
+		pages++
+
+		for _, c := range content {
+			// Shortcuts point to folders found under their real parent.
+			if !c.IsFolder() || c.Shortcut {
+				continue
+			}
+
+			if p.opts.Walk != nil {
+				walk, err := p.opts.Walk(ctx, c, folder.depth+1)
+				if err != nil {
+					l.Debug("baton-lucidchart: skipped prefetching a folder", zap.String("folder_id", c.ID()), zap.Error(err))
+					continue
+				}
+
+				if !walk {
+					continue
+				}
+			}
+
+			subfolders = append(subfolders, prefetchFolder{id: c.ID(), depth: folder.depth + 1})
+		}
+
+		if nextToken == "" {
+			return subfolders, pages
+		}
+
+		pageToken = nextToken
+	}
+}
+
+// prefetch lists a page of a folder and keeps it pending, unless FolderContent already requested it.
+func (p *prefetchRun) prefetch(ctx context.Context, folderId, pageToken string) ([]FolderContent, string, error) {
+	key := folderContentCacheKey{folderId: folderId, pageToken: pageToken}
+
+	if content, nextToken, ok := p.client.folderContentCache.get(folderId, pageToken); ok {
+		return content, nextToken, nil
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+
+	p.mutex.Lock()
+	if call, ok := p.pending[key]; ok {
+		p.mutex.Unlock()
+		<-p.slots
+		return call.content, call.nextToken, call.err
+	}
+
+	if call, ok := p.calls[key]; ok {
+		p.mutex.Unlock()
+		<-p.slots
+		<-call.done
+		return call.content, call.nextToken, call.err
+	}
+
+	// FolderContent may have requested the page while the prefetch was waiting for a slot. Its call caches
+	// the page before it is dropped.
+	if content, nextToken, ok := p.client.folderContentCache.get(folderId, pageToken); ok {
+		p.mutex.Unlock()
+		<-p.slots
+		return content, nextToken, nil
+	}
+
+	// The cache may have dropped a page FolderContent served. The prefetch still needs it to find the
+	// subfolders, but doesn't keep it.
+	_, served := p.served[key]
+
+	call := &folderContentCall{done: make(chan struct{}), served: served, slot: !served}
+	p.calls[key] = call
+	p.mutex.Unlock()
+
+	if served {
+		<-p.slots
+	}
+
+	p.request(ctx, key, call)
+
+	return call.content, call.nextToken, call.err
+}
+
+// get returns a page of a folder for FolderContent, from the pending pages, the request of the prefetch,
+// or a new request.
+func (p *prefetchRun) get(ctx context.Context, folderId, path, pageToken string) ([]FolderContent, string, error) {
+	key := folderContentCacheKey{folderId: folderId, pageToken: pageToken}
+
+	p.mutex.Lock()
+	p.served[key] = struct{}{}
+
+	if call, ok := p.pending[key]; ok {
+		delete(p.pending, key)
+		p.mutex.Unlock()
+		<-p.slots
+
+		return call.content, call.nextToken, nil
+	}
+
+	call, ok := p.calls[key]
+	if ok {
+		call.served = true
+		p.mutex.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		}
+
+		return call.content, call.nextToken, call.err
+	}
+
+	call = &folderContentCall{done: make(chan struct{}), served: true}
+	p.calls[key] = call
+	p.mutex.Unlock()
+
+	p.requestPath(ctx, key, path, call)
+
+	return call.content, call.nextToken, call.err
+}
+
+func (p *prefetchRun) request(ctx context.Context, key folderContentCacheKey, call *folderContentCall) {
+	p.requestPath(ctx, key, fmt.Sprintf(FolderContentPath, key.folderId), call)
+}
+
+// requestPath requests the page of the call and either keeps it pending or releases its slot.
+func (p *prefetchRun) requestPath(ctx context.Context, key folderContentCacheKey, path string, call *folderContentCall) {
+	call.content, call.nextToken, call.err = newPager[FolderContent](p.client, path, LucidAuthTypeApiKey).page(ctx, key.pageToken)
+
+	p.mutex.Lock()
+	keep := call.slot && !call.served && call.err == nil
+	if keep {
+		p.pending[key] = call
+	} else if call.err == nil {
+		// The page is cached before the call is dropped, so that the prefetch never requests it again.
+		p.client.folderContentCache.set(key.folderId, key.pageToken, call.content, call.nextToken)
+	}
+	delete(p.calls, key)
+	p.mutex.Unlock()
+
+	if call.slot && !keep {
+		<-p.slots
+	}
+
+	close(call.done)
+}
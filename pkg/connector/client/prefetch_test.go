@@ -0,0 +1,233 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// prefetchTree is a folder tree, by folder id, whose content is served one item per page.
+var prefetchTree = map[string][]FolderContent{
+	"root": {
+		{Id: 1, Type: FolderTypeFolder, Name: "Projects"},
+		{Id: 2, Type: FolderTypeTeam, Name: "Archive"},
+		{Id: 3, Type: FolderTypeFolder, Name: "Shortcut", Shortcut: true},
+	},
+	"1": {
+		{Id: 3, Type: FolderTypeFolder, Name: "Designs"},
+		{Id: "doc", Type: "document", Name: "Architecture"},
+	},
+	"2": {
+		{Id: "old", Type: "document", Name: "Old"},
+	},
+	"3": {
+		{Id: "wireframes", Type: "document", Name: "Wireframes"},
+	},
+}
+
+// newTreeServer serves the content of the prefetch tree and counts the requests of each page.
+func newTreeServer(t *testing.T) (*httptest.Server, func() map[string]int) {
+	t.Helper()
+
+	var (
+		mutex    sync.Mutex
+		requests = map[string]int{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[r.URL.RequestURI()]++
+		mutex.Unlock()
+
+		folderId := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/folders/"), "/contents")
+		index, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+
+		content := prefetchTree[folderId]
+		if index+1 < len(content) {
+			w.Header().Set("Link", fmt.Sprintf("<http://%s%s?pageToken=%d>; rel=\"next\"", r.Host, r.URL.Path, index+1))
+		}
+
+		page := []FolderContent{}
+		if index < len(content) {
+			page = content[index : index+1]
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(page))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() map[string]int {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		rv := map[string]int{}
+		for uri, count := range requests {
+			rv[uri] = count
+		}
+
+		return rv
+	}
+}
+
+// listTree lists the tree like the syncer does, through FolderContent, and returns the names found.
+func listTree(t *testing.T, c *LucidchartClient) []string {
+	t.Helper()
+
+	var names []string
+
+	queue := []string{"root"}
+	for len(queue) > 0 {
+		folderId := queue[0]
+		queue = queue[1:]
+
+		err := EachPage(
+			context.Background(),
+			func(ctx context.Context, pageToken string) ([]FolderContent, string, error) {
+				return c.FolderContent(ctx, folderId, pageToken)
+			},
+			func(content []FolderContent) (bool, error) {
+				for _, item := range content {
+					names = append(names, item.Name)
+					if item.IsFolder() && !item.Shortcut {
+						queue = append(queue, item.ID())
+					}
+				}
+
+				return true, nil
+			},
+		)
+		require.NoError(t, err)
+	}
+
+	return names
+}
+
+func newPrefetchClient(t *testing.T, server *httptest.Server, opts PrefetchOptions) *LucidchartClient {
+	t.Helper()
+
+	c := newTestClient(t, server)
+	WithPrefetch(opts)(c)
+
+	return c
+}
+
+// currentRun returns the prefetch of the current sync.
+func currentRun(c *LucidchartClient) *prefetchRun {
+	c.prefetcher.mutex.Lock()
+	defer c.prefetcher.mutex.Unlock()
+
+	return c.prefetcher.current
+}
+
+func TestPrefetch(t *testing.T) {
+	want := []string{"Projects", "Archive", "Shortcut", "Designs", "Architecture", "Old", "Wireframes"}
+
+	tests := []struct {
+		name string
+		opts PrefetchOptions
+		// rps is the rate limit of the client.
+		rps int
+		// wait waits for the end of the prefetch before listing the tree.
+		wait bool
+	}{
+		{
+			name: "listed after the prefetch",
+			opts: PrefetchOptions{Concurrency: 4},
+			wait: true,
+		},
+		{
+			name: "listed during the prefetch",
+			opts: PrefetchOptions{Concurrency: 4},
+		},
+		{
+			name: "listed during a rate limited prefetch",
+			opts: PrefetchOptions{Concurrency: 2},
+			rps:  1000,
+		},
+		{
+			name: "listed during a prefetch waiting for pages to be served",
+			opts: PrefetchOptions{Concurrency: 4, MaxPendingPages: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newTreeServer(t)
+			c := newPrefetchClient(t, server, tt.opts)
+			WithRequestsPerSecond(tt.rps)(c)
+
+			c.StartSync(context.Background())
+			if tt.wait {
+				<-currentRun(c).done
+			}
+
+			require.Equal(t, want, listTree(t, c))
+			<-currentRun(c).done
+
+			// Every page was requested once, by either the prefetch or FolderContent.
+			for uri, count := range requests() {
+				require.Equal(t, 1, count, uri)
+			}
+			require.Len(t, requests(), 7)
+		})
+	}
+}
+
+func TestPrefetchWalk(t *testing.T) {
+	server, requests := newTreeServer(t)
+	c := newPrefetchClient(t, server, PrefetchOptions{
+		Concurrency: 2,
+		Walk: func(_ context.Context, folder FolderContent, depth int) (bool, error) {
+			if folder.ID() == "3" {
+				return false, errors.New("boom")
+			}
+
+			return folder.Type != FolderTypeTeam, nil
+		},
+	})
+
+	c.StartSync(context.Background())
+	<-currentRun(c).done
+
+	// Neither the rejected folder nor the one that failed are listed.
+	require.NotContains(t, requests(), "/folders/2/contents")
+	require.NotContains(t, requests(), "/folders/3/contents")
+	require.Len(t, requests(), 5)
+}
+
+func TestPrefetchRestart(t *testing.T) {
+	server, requests := newTreeServer(t)
+	c := newPrefetchClient(t, server, PrefetchOptions{Concurrency: 2, MaxPendingPages: 1})
+
+	// Nothing is listed, so the prefetch waits with a page pending.
+	c.StartSync(context.Background())
+	previous := currentRun(c)
+	require.Eventually(t, func() bool {
+		previous.mutex.Lock()
+		defer previous.mutex.Unlock()
+
+		return len(previous.pending) == 1
+	}, time.Second, time.Millisecond)
+
+	// The next sync stops it and frees its pending page.
+	c.StartSync(context.Background())
+	<-previous.done
+	require.Empty(t, previous.pending)
+	require.Empty(t, previous.slots)
+	require.NotSame(t, previous, currentRun(c))
+
+	require.Len(t, listTree(t, c), 7)
+	<-currentRun(c).done
+	require.Len(t, requests(), 7)
+}
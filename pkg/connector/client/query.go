@@ -4,113 +4,187 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+const rootFolderId = "root"
+
 var (
+	TokenPath                         = "/oauth2/token"
 	GetUsersPath                      = "/users"
+	GetTeamsPath                      = "/teams"
+	GetDocumentPath                   = "/documents/%s"
+	GetFolderPath                     = "/folders/%s"
+	ListAuditLogsPath                 = "/auditLogs"
 	RootFolderContentPath             = "/folders/root/contents"
 	FolderContentPath                 = "/folders/%s/contents"
 	ListFolderUserCollaboratorsPath   = "/folders/%s/shares/users"
 	ListDocumentUserCollaboratorsPath = "/documents/%s/shares/users"
 	UpsertFolderUserCollaboratorPath  = "/folders/%s/shares/users/%s"
 	DeleteFolderUserCollaboratorPath  = "/folders/%s/shares/users/%s"
-
-	UpsertDocumentUserCollaboratorPath = "/documents/%s/shares/users/%s"
-	DeleteDocumentUserCollaboratorPath = "/documents/%s/shares/users/%s"
+	UpsertFolderGroupCollaboratorPath = "/folders/%s/shares/groups/%s"
+
+	UpsertDocumentUserCollaboratorPath  = "/documents/%s/shares/users/%s"
+	DeleteDocumentUserCollaboratorPath  = "/documents/%s/shares/users/%s"
+	UpsertDocumentGroupCollaboratorPath = "/documents/%s/shares/groups/%s"
+	TransferDocumentOwnershipPath       = "/documents/%s/owner"
+	CreateDocumentPath                  = "/documents"
+
+	CreateFolderPath = "/folders"
+	UpdateFolderPath = "/folders/%s"
+
+	TrashFolderPath     = "/folders/%s/trash"
+	RestoreFolderPath   = "/folders/%s/restore"
+	DeleteFolderPath    = "/folders/%s"
+	TrashDocumentPath   = "/documents/%s/trash"
+	RestoreDocumentPath = "/documents/%s/restore"
+	DeleteDocumentPath  = "/documents/%s"
+
+	ListDocumentShareLinksPath  = "/documents/%s/shares/shareLinks"
+	GetDocumentShareLinkPath    = "/documents/%s/shares/shareLinks/%s"
+	CreateDocumentShareLinkPath = "/documents/%s/shares/shareLinks"
+	UpdateDocumentShareLinkPath = "/documents/%s/shares/shareLinks/%s"
+	DeleteDocumentShareLinkPath = "/documents/%s/shares/shareLinks/%s"
 )
 
+// ListUser returns the users of the account. Without OAuth2 credentials, they are listed with the API key,
+// which needs the permission to read the users of the account.
 func (c *LucidchartClient) ListUser(ctx context.Context, pageToken string) ([]User, string, error) {
-	var response []User
-
-	req, err := c.newRequest(ctx, LucidchartApiUrl, http.MethodGet, GetUsersPath, nil, LucidAuthTypeOAuth2)
-	if err != nil {
-		return nil, "", err
+	authType := LucidAuthTypeOAuth2
+	if !c.HasOAuth2() {
+		authType = LucidAuthTypeApiKey
 	}
 
-	addPageToken(req, pageToken)
+	return newPager[User](c, GetUsersPath, authType).page(ctx, pageToken)
+}
 
-	nextToken, err := c.doRequest(ctx, req, &response, false)
-	if err != nil {
-		return nil, "", err
+func (c *LucidchartClient) ListTeams(ctx context.Context, pageToken string) ([]Team, string, error) {
+	return newPager[Team](c, GetTeamsPath, LucidAuthTypeOAuth2).page(ctx, pageToken)
+}
+
+// ListAuditLogs returns the events of the account audit log that happened since the given time, oldest first.
+// The page size overrides the one of the client when set.
+func (c *LucidchartClient) ListAuditLogs(ctx context.Context, from time.Time, pageSize int, pageToken string) ([]AuditLogEvent, string, error) {
+	p := newPager[AuditLogEvent](c, ListAuditLogsPath, LucidAuthTypeOAuth2).
+		withQuery("from", from.UTC().Format(time.RFC3339Nano))
+	if pageSize > 0 {
+		p.withQuery("pageSize", strconv.Itoa(pageSize))
 	}
 
-	return response, nextToken, nil
+	return p.page(ctx, pageToken)
 }
 
+// RootFolderContent returns a page of the content of the root folder. It shares its cached pages with FolderContent.
 func (c *LucidchartClient) RootFolderContent(ctx context.Context, pageToken string) ([]FolderContent, string, error) {
-	var response []FolderContent
+	return c.folderContent(ctx, rootFolderId, RootFolderContentPath, pageToken)
+}
 
-	req, err := c.newRequest(ctx, LucidchartApiUrl, http.MethodGet, RootFolderContentPath, nil, LucidAuthTypeApiKey)
-	if err != nil {
-		return nil, "", err
+// FolderContent returns a page of the content of a folder. Pages are cached, so listing the folders and
+// the documents of the same folder only requests each page once.
+func (c *LucidchartClient) FolderContent(ctx context.Context, folderId string, pageToken string) ([]FolderContent, string, error) {
+	return c.folderContent(ctx, folderId, fmt.Sprintf(FolderContentPath, folderId), pageToken)
+}
+
+// ListFolderContent returns a page of the content of a folder as it currently is, without the pages cached or
+// prefetched for the sync, which FolderContent serves.
+func (c *LucidchartClient) ListFolderContent(ctx context.Context, folderId string, pageToken string) ([]FolderContent, string, error) {
+	return newPager[FolderContent](c, fmt.Sprintf(FolderContentPath, folderId), LucidAuthTypeApiKey).page(ctx, pageToken)
+}
+
+func (c *LucidchartClient) folderContent(ctx context.Context, folderId, path, pageToken string) ([]FolderContent, string, error) {
+	if content, nextToken, ok := c.folderContentCache.get(folderId, pageToken); ok {
+		return content, nextToken, nil
 	}
 
-	addPageToken(req, pageToken)
+	var (
+		response  []FolderContent
+		nextToken string
+		err       error
+	)
 
-	nextToken, err := c.doRequest(ctx, req, &response, false)
+	if c.prefetcher != nil {
+		response, nextToken, err = c.prefetcher.get(ctx, folderId, path, pageToken)
+	} else {
+		response, nextToken, err = newPager[FolderContent](c, path, LucidAuthTypeApiKey).page(ctx, pageToken)
+	}
 	if err != nil {
 		return nil, "", err
 	}
 
+	c.folderContentCache.set(folderId, pageToken, response, nextToken)
+
 	return response, nextToken, nil
 }
 
-func (c *LucidchartClient) FolderContent(ctx context.Context, folderId string, pageToken string) ([]FolderContent, string, error) {
-	var response []FolderContent
+func (c *LucidchartClient) ListFolderUserCollaborators(ctx context.Context, folderId string, pageToken string) ([]FolderUserCollaboration, string, error) {
+	path := fmt.Sprintf(ListFolderUserCollaboratorsPath, folderId)
 
-	path := fmt.Sprintf(FolderContentPath, folderId)
+	return newPager[FolderUserCollaboration](c, path, LucidAuthTypeApiKey).page(ctx, pageToken)
+}
 
-	req, err := c.newRequest(ctx, LucidchartApiUrl, http.MethodGet, path, nil, LucidAuthTypeApiKey)
+func (c *LucidchartClient) ListDocumentUserCollaborators(ctx context.Context, documentId string, pageToken string) ([]DocumentUserCollaboration, string, error) {
+	path := fmt.Sprintf(ListDocumentUserCollaboratorsPath, documentId)
+
+	return newPager[DocumentUserCollaboration](c, path, LucidAuthTypeApiKey).page(ctx, pageToken)
+}
+
+func (c *LucidchartClient) ListDocumentShareLinks(ctx context.Context, documentId string, pageToken string) ([]DocumentShareLink, string, error) {
+	path := fmt.Sprintf(ListDocumentShareLinksPath, documentId)
+
+	return newPager[DocumentShareLink](c, path, LucidAuthTypeApiKey).page(ctx, pageToken)
+}
+
+func (c *LucidchartClient) GetDocumentShareLink(ctx context.Context, documentId, shareLinkId string) (*DocumentShareLink, error) {
+	var response DocumentShareLink
+
+	path := fmt.Sprintf(GetDocumentShareLinkPath, documentId, shareLinkId)
+
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodGet, path, nil, LucidAuthTypeApiKey)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	addPageToken(req, pageToken)
-
-	nextToken, err := c.doRequest(ctx, req, &response, false)
+	_, err = c.doRequest(ctx, req, &response, false)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	return response, nextToken, nil
+	return &response, nil
 }
 
-func (c *LucidchartClient) ListFolderUserCollaborators(ctx context.Context, folderId string, pageToken string) ([]FolderUserCollaboration, string, error) {
-	var response []FolderUserCollaboration
+func (c *LucidchartClient) GetDocument(ctx context.Context, documentId string) (*Document, error) {
+	var response Document
 
-	path := fmt.Sprintf(ListFolderUserCollaboratorsPath, folderId)
+	path := fmt.Sprintf(GetDocumentPath, documentId)
 
-	req, err := c.newRequest(ctx, LucidchartApiUrl, http.MethodGet, path, nil, LucidAuthTypeApiKey)
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodGet, path, nil, LucidAuthTypeApiKey)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	addPageToken(req, pageToken)
-
-	nextToken, err := c.doRequest(ctx, req, &response, false)
+	_, err = c.doRequest(ctx, req, &response, false)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	return response, nextToken, nil
+	return &response, nil
 }
 
-func (c *LucidchartClient) ListDocumentUserCollaborators(ctx context.Context, documentId string, pageToken string) ([]DocumentUserCollaboration, string, error) {
-	var response []DocumentUserCollaboration
+func (c *LucidchartClient) GetFolder(ctx context.Context, folderId string) (*Folder, error) {
+	var response Folder
 
-	path := fmt.Sprintf(ListDocumentUserCollaboratorsPath, documentId)
+	path := fmt.Sprintf(GetFolderPath, folderId)
 
-	req, err := c.newRequest(ctx, LucidchartApiUrl, http.MethodGet, path, nil, LucidAuthTypeApiKey)
+	req, err := c.newRequest(ctx, c.baseUrl, http.MethodGet, path, nil, LucidAuthTypeApiKey)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	addPageToken(req, pageToken)
-
-	nextToken, err := c.doRequest(ctx, req, &response, false)
+	_, err = c.doRequest(ctx, req, &response, false)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	return response, nextToken, nil
+	return &response, nil
 }
@@ -0,0 +1,205 @@
+package connector
+
+import (
+	"context"
+	"sync"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+)
+
+// defaultCollaboratorLoaderMaxPending is the number of documents whose loaded collaborators are kept until
+// their grants are synced. The loader waits once there are as many.
+const defaultCollaboratorLoaderMaxPending = 1000
+
+// collaboratorLoad is the load of every page of collaborators of a document.
+type collaboratorLoad struct {
+	documentId string
+	done       chan struct{}
+	pages      [][]client.DocumentUserCollaboration
+	err        error
+
+	// started is set once a worker picked the document, and taken once Grants took it, the load of a
+	// document taken before it started being skipped.
+	started bool
+	taken   bool
+}
+
+// collaboratorLoader loads the collaborators of the documents about to be granted by the syncer, with a
+// pool of workers, so that their grants are synced without waiting for Lucid. Lucid has no bulk endpoint for
+// the shares of many documents, so each document still costs its own requests, but they are sent
+// concurrently instead of one at a time, within the rate limit of the client. The documents are enqueued
+// by the grants of their neighbors, see documentBuilder.loadAround, so only a few are loaded at a time.
+type collaboratorLoader struct {
+	client      *client.LucidchartClient
+	concurrency int
+
+	mutex sync.Mutex
+	sync  *collaboratorSync
+}
+
+// collaboratorSync are the loads of a sync. The connector lives across syncs, so they are dropped once the
+// next sync starts, and their workers, which run in the context of the sync, stop.
+type collaboratorSync struct {
+	ctx context.Context
+
+	// slots bounds the number of documents being loaded or waiting for Grants.
+	slots chan struct{}
+
+	queue   []*collaboratorLoad
+	loads   map[string]*collaboratorLoad
+	workers int
+}
+
+func newCollaboratorLoader(c *client.LucidchartClient, concurrency int) *collaboratorLoader {
+	return &collaboratorLoader{
+		client:      c,
+		concurrency: concurrency,
+	}
+}
+
+// current returns the loads of the current sync, starting over when another sync started since the last
+// call. It must be called with the mutex held.
+func (o *collaboratorLoader) current() *collaboratorSync {
+	ctx := o.client.SyncContext()
+	if o.sync == nil || o.sync.ctx != ctx {
+		o.sync = &collaboratorSync{
+			ctx:   ctx,
+			slots: make(chan struct{}, defaultCollaboratorLoaderMaxPending),
+			loads: make(map[string]*collaboratorLoad),
+		}
+	}
+
+	return o.sync
+}
+
+// window is the number of documents loaded on each side of the document being granted, enough to keep every
+// worker busy.
+func (o *collaboratorLoader) window() int {
+	return 2 * o.concurrency
+}
+
+// enqueue starts loading the collaborators of the documents in the background, in the context of the sync.
+func (o *collaboratorLoader) enqueue(_ context.Context, documentIds []string) {
+	if o == nil {
+		return
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	s := o.current()
+
+	for _, documentId := range documentIds {
+		if _, ok := s.loads[documentId]; ok {
+			continue
+		}
+
+		load := &collaboratorLoad{documentId: documentId, done: make(chan struct{})}
+		s.loads[documentId] = load
+		s.queue = append(s.queue, load)
+	}
+
+	for s.workers < o.concurrency && s.workers < len(s.queue) {
+		s.workers++
+		go o.work(s)
+	}
+}
+
+// take returns the pages of collaborators of a document, waiting for its load, and false when the document
+// wasn't loaded, the caller then listing the collaborators itself.
+func (o *collaboratorLoader) take(ctx context.Context, documentId string) ([][]client.DocumentUserCollaboration, bool, error) {
+	if o == nil {
+		return nil, false, nil
+	}
+
+	o.mutex.Lock()
+	s := o.current()
+	load, ok := s.loads[documentId]
+	if ok {
+		delete(s.loads, documentId)
+		load.taken = true
+	}
+	started := ok && load.started
+	o.mutex.Unlock()
+
+	if !started {
+		return nil, false, nil
+	}
+
+	select {
+	case <-load.done:
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+
+	<-s.slots
+
+	// The caller lists the collaborators again, surfacing the error if it persists.
+	if load.err != nil {
+		return nil, false, nil
+	}
+
+	return load.pages, true, nil
+}
+
+// work loads the queued documents of a sync until there are none left or the sync is over.
+func (o *collaboratorLoader) work(s *collaboratorSync) {
+	for {
+		select {
+		case s.slots <- struct{}{}:
+		case <-s.ctx.Done():
+			o.stop(s)
+			return
+		}
+
+		load, ok := o.next(s)
+		if !ok {
+			<-s.slots
+			return
+		}
+
+		load.err = client.EachPage(
+			s.ctx,
+			func(ctx context.Context, pageToken string) ([]client.DocumentUserCollaboration, string, error) {
+				return o.client.ListDocumentUserCollaborators(ctx, load.documentId, pageToken)
+			},
+			func(collaborators []client.DocumentUserCollaboration) (bool, error) {
+				load.pages = append(load.pages, collaborators)
+				return true, nil
+			},
+		)
+		close(load.done)
+	}
+}
+
+// next pops the next document to load, skipping the ones already taken, and returns false once the queue
+// is empty or the sync is over, the worker then stopping.
+func (o *collaboratorLoader) next(s *collaboratorSync) (*collaboratorLoad, bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for len(s.queue) > 0 && s.ctx.Err() == nil {
+		load := s.queue[0]
+		s.queue = s.queue[1:]
+
+		if load.taken {
+			continue
+		}
+
+		load.started = true
+
+		return load, true
+	}
+
+	s.workers--
+
+	return nil, false
+}
+
+// stop retires a worker of a sync that is over.
+func (o *collaboratorLoader) stop(s *collaboratorSync) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	s.workers--
+}
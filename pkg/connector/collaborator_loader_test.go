@@ -0,0 +1,157 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+	"github.com/conductorone/baton-lucidchart/pkg/lucidtest"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+func TestCollaboratorLoader(t *testing.T) {
+	ctx := context.Background()
+
+	c, server := newTestConnector(t, syncFixtures())
+	loader := newCollaboratorLoader(c.client, 2)
+
+	// Documents that weren't enqueued are listed by the caller.
+	_, ok, err := loader.take(ctx, "doc")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	loader.enqueue(ctx, []string{"doc", "wireframes"})
+	loader.enqueue(ctx, []string{"doc"})
+
+	// Documents are only taken from the loader once their load started.
+	require.Eventually(t, func() bool {
+		return server.Count(http.MethodGet, "/documents/*/shares/users") == 5
+	}, time.Second, time.Millisecond)
+
+	pages, ok, err := loader.take(ctx, "doc")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, pages, 3)
+	require.Equal(t, 2, pages[1][0].UserId)
+
+	pages, ok, err = loader.take(ctx, "wireframes")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, pages, 2)
+
+	// A document is only taken once.
+	_, ok, err = loader.take(ctx, "doc")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.Equal(t, 3, server.Count(http.MethodGet, "/documents/doc/shares/users"))
+	require.Empty(t, loader.sync.slots)
+}
+
+func TestCollaboratorLoaderFailure(t *testing.T) {
+	ctx := context.Background()
+
+	c, server := newTestConnector(t, syncFixtures())
+	server.Fail(lucidtest.Failure{Method: http.MethodGet, Path: "/documents/doc/shares/users", Status: http.StatusNotFound, Times: 1})
+
+	loader := newCollaboratorLoader(c.client, 1)
+
+	loader.enqueue(ctx, []string{"doc"})
+	require.Eventually(t, func() bool {
+		return server.Count(http.MethodGet, "/documents/doc/shares/users") > 0
+	}, time.Second, time.Millisecond)
+
+	// A failed load is left to the caller, which surfaces the error if it persists.
+	_, ok, err := loader.take(ctx, "doc")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Empty(t, loader.sync.slots)
+}
+
+func TestCollaboratorLoaderSync(t *testing.T) {
+	ctx := context.Background()
+
+	c, server := newTestConnector(t, syncFixtures(), WithRequestsPerSecond(20))
+	loader := newCollaboratorLoader(c.client, 1)
+
+	c.client.StartSync(ctx)
+	loader.enqueue(ctx, []string{"doc", "wireframes"})
+	previous := loader.sync
+	require.Eventually(t, func() bool {
+		return server.Count(http.MethodGet, "/documents/doc/shares/users") > 0
+	}, time.Second, time.Millisecond)
+
+	// The next sync drops the loads of the previous one and stops its workers.
+	c.client.StartSync(ctx)
+
+	_, ok, err := loader.take(ctx, "wireframes")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NotSame(t, previous, loader.sync)
+
+	require.Eventually(t, func() bool {
+		loader.mutex.Lock()
+		defer loader.mutex.Unlock()
+
+		return previous.workers == 0
+	}, time.Second, time.Millisecond)
+	require.Zero(t, server.Count(http.MethodGet, "/documents/wireframes/shares/users"))
+}
+
+func TestDocumentBuilderLoadsAround(t *testing.T) {
+	fixtures := testFixtures()
+	fixtures.FolderContents["100"] = nil
+	for i := 0; i < 6; i++ {
+		id := fmt.Sprintf("d%d", i)
+		fixtures.FolderContents["100"] = append(fixtures.FolderContents["100"],
+			client.FolderContent{Id: id, Type: "document", Name: id, Product: client.ProductLucidchart})
+		fixtures.Documents[id] = client.Document{DocumentId: id, Title: id, Product: client.ProductLucidchart, Parent: 100}
+		fixtures.DocumentCollaborators[id] = []client.DocumentUserCollaboration{{DocumentId: id, UserId: 2, Role: "view"}}
+	}
+
+	c, server := newTestConnector(t, fixtures)
+
+	folder, err := rs.NewResourceID(folderResourceType, "100")
+	require.NoError(t, err)
+
+	// A worker loads up to 2 documents on each side of the one being granted.
+	documents := newDocumentBuilder(c.client, client.ProductLucidchart, "", c.folderScope, c.trashedPolicy, false, false, newCollaboratorLoader(c.client, 1))
+
+	require.Empty(t, listAll(t, documents, nil))
+	docs := listAll(t, documents, folder)
+	require.Len(t, docs, 6)
+
+	// Listing the documents loads nothing, the loads start with the grants.
+	require.Zero(t, server.Count(http.MethodGet, "/documents/*/shares/users"))
+
+	shares := func(id string) int {
+		return server.Count(http.MethodGet, "/documents/"+id+"/shares/users")
+	}
+
+	require.Len(t, grantsAll(t, documents, docs[2]), 1)
+	require.Eventually(t, func() bool {
+		return shares("d0") == 1 && shares("d1") == 1 && shares("d3") == 1 && shares("d4") == 1
+	}, time.Second, time.Millisecond)
+	require.Zero(t, shares("d5"))
+
+	// The documents granted next use their load, and load the following ones, except the ones granted.
+	require.Len(t, grantsAll(t, documents, docs[3]), 1)
+	require.Eventually(t, func() bool {
+		return shares("d5") == 1
+	}, time.Second, time.Millisecond)
+
+	for _, document := range docs {
+		if document.Id.Resource != "d2" && document.Id.Resource != "d3" {
+			require.Len(t, grantsAll(t, documents, document), 1)
+		}
+	}
+
+	for i := 0; i < 6; i++ {
+		require.Equal(t, 1, shares(fmt.Sprintf("d%d", i)))
+	}
+}
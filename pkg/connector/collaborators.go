@@ -0,0 +1,108 @@
+package connector
+
+import (
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+)
+
+// collaboratorSettings are the initial owner and collaborators requested when a folder or a document is
+// created. They are sent as a structpb.Struct annotation with the keys owner, the id of the owning user,
+// collaborators, an object mapping user ids to roles, and groups, an object mapping group ids to roles.
+type collaboratorSettings struct {
+	Owner         string
+	Collaborators map[string]string
+	Groups        map[string]string
+}
+
+// sortedIds returns the ids of a collaborator map in a stable order, so they are added deterministically.
+func sortedIds(collaborators map[string]string) []string {
+	ids := make([]string, 0, len(collaborators))
+	for id := range collaborators {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// parseCollaboratorSettings reads the collaborator settings of the annotations, checking the roles against
+// the catalog of the created resource. Settings of other keys are returned for the caller to handle.
+func parseCollaboratorSettings(annos annotations.Annotations, roles roleCatalog) (*collaboratorSettings, map[string]interface{}, error) {
+	settings := &collaboratorSettings{
+		Collaborators: make(map[string]string),
+		Groups:        make(map[string]string),
+	}
+
+	fields := &structpb.Struct{}
+	ok, err := annos.Pick(fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !ok {
+		return settings, nil, nil
+	}
+
+	others := make(map[string]interface{})
+
+	for key, value := range fields.AsMap() {
+		switch key {
+		case "owner":
+			owner, ok := value.(string)
+			if !ok || owner == "" {
+				return nil, nil, invalidSetting(key, value)
+			}
+			settings.Owner = owner
+
+		case "collaborators", "groups":
+			collaborators, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, nil, invalidSetting(key, value)
+			}
+
+			target := settings.Collaborators
+			if key == "groups" {
+				target = settings.Groups
+			}
+
+			for id, rawRole := range collaborators {
+				role, ok := rawRole.(string)
+				if !ok || !roles.has(role) {
+					return nil, nil, status.Errorf(codes.InvalidArgument, "baton-lucidchart: invalid role %v for collaborator %s", rawRole, id)
+				}
+				target[id] = role
+			}
+
+		default:
+			others[key] = value
+		}
+	}
+
+	return settings, others, nil
+}
+
+func invalidSetting(key string, value interface{}) error {
+	return status.Errorf(codes.InvalidArgument, "baton-lucidchart: invalid value %v for setting %s", value, key)
+}
+
+// rejectUnknownSettings fails on the settings no one handled, naming the first one in alphabetical order.
+func rejectUnknownSettings(kind string, settings map[string]interface{}) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return status.Errorf(codes.InvalidArgument, "baton-lucidchart: unknown %s setting %s", kind, keys[0])
+}
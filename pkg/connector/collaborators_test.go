@@ -0,0 +1,73 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+)
+
+func TestParseCollaboratorSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		fields     map[string]interface{}
+		want       *collaboratorSettings
+		wantOthers map[string]interface{}
+		wantErr    bool
+	}{
+		{
+			name: "no settings",
+			want: &collaboratorSettings{Collaborators: map[string]string{}, Groups: map[string]string{}},
+		},
+		{
+			name: "owner and collaborators",
+			fields: map[string]interface{}{
+				"owner":         "1",
+				"collaborators": map[string]interface{}{"2": "edit", "3": "view"},
+				"groups":        map[string]interface{}{"4": "comment"},
+				"template":      "abc",
+			},
+			want: &collaboratorSettings{
+				Owner:         "1",
+				Collaborators: map[string]string{"2": "edit", "3": "view"},
+				Groups:        map[string]string{"4": "comment"},
+			},
+			wantOthers: map[string]interface{}{"template": "abc"},
+		},
+		{
+			name:    "unknown role",
+			fields:  map[string]interface{}{"collaborators": map[string]interface{}{"2": "admin"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid owner",
+			fields:  map[string]interface{}{"owner": 1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var annos annotations.Annotations
+			if tt.fields != nil {
+				fields, err := structpb.NewStruct(tt.fields)
+				require.NoError(t, err)
+				annos.Update(fields)
+			}
+
+			settings, others, err := parseCollaboratorSettings(annos, folderRoles)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, settings)
+			if tt.wantOthers != nil {
+				require.Equal(t, tt.wantOthers, others)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package connector
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
@@ -10,19 +11,197 @@ import (
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// DocumentSyncMode selects which documents are synced.
+type DocumentSyncMode string
+
+const (
+	// DocumentSyncAll syncs every document.
+	DocumentSyncAll DocumentSyncMode = "all"
+	// DocumentSyncNone only syncs folders, for accounts governed at the folder level.
+	DocumentSyncNone DocumentSyncMode = "none"
+	// DocumentSyncDirectShares only syncs the documents shared differently than their parent folder. It
+	// makes as many requests as syncing every document: the collaborators of every document are listed
+	// during the resource phase to find its direct shares, on top of those of every folder. It only saves
+	// the resources and grants of the documents skipped. The collaborators of the documents kept are reused
+	// for their grants, up to defaultCollaboratorLoaderMaxPending documents, the grants of the others
+	// listing them again.
+	DocumentSyncDirectShares DocumentSyncMode = "direct-shares"
 )
 
 type Connector struct {
-	client *client.LucidchartClient
+	client           *client.LucidchartClient
+	shareLinkPolicy  *ShareLinkPolicy
+	folderScope      *folderScopeTracker
+	documentSyncMode DocumentSyncMode
+	trashedPolicy    TrashedContentPolicy
+	// permanentDelete deletes folders and documents permanently, instead of only trashing them.
+	permanentDelete bool
+	// documentTemplates are the ids of the documents copied to create documents, by product.
+	documentTemplates map[string]string
+	// baseUrl is the URL of the Lucid API, the public one when empty.
+	baseUrl string
+	// authTypes are the auth types configured for Lucid endpoints, instead of their default one.
+	authTypes map[client.LucidEndpoint]client.LucidAuthType
+	// cassetteMode and cassettePath are set to record the requests to Lucid into a cassette, or replay them from it.
+	cassetteMode client.CassetteMode
+	cassettePath string
+	// pageSize is the number of items requested in each page from Lucid, Lucid's default when 0.
+	pageSize int
+	// prefetchConcurrency is the number of folders the prefetch lists at the same time, no prefetch when 0.
+	prefetchConcurrency int
+	// collaboratorConcurrency is the number of documents whose collaborators are loaded at the same time
+	// ahead of their grants, no loading when 0.
+	collaboratorConcurrency int
+	collaborators           *collaboratorLoader
+	// requestsPerSecond caps every request sent to Lucid, no limit when 0.
+	requestsPerSecond int
+}
+
+// Option configures the optional behaviors of the connector.
+type Option func(*Connector)
+
+// WithFolderScope restricts the folders, and so the documents, that are synced.
+func WithFolderScope(scope FolderScope) Option {
+	return func(c *Connector) {
+		c.folderScope = newFolderScopeTracker(scope)
+	}
+}
+
+// WithDocumentSyncMode selects which documents are synced, all of them by default.
+func WithDocumentSyncMode(mode DocumentSyncMode) Option {
+	return func(c *Connector) {
+		c.documentSyncMode = mode
+	}
+}
+
+// WithTrashedContentPolicy selects how trashed folders and documents are synced, they are included by default.
+func WithTrashedContentPolicy(policy TrashedContentPolicy) Option {
+	return func(c *Connector) {
+		c.trashedPolicy = policy
+	}
+}
+
+// WithPermanentDelete deletes folders and documents permanently when they are deleted, instead of moving them
+// to the trash, where they can still be restored.
+func WithPermanentDelete(permanentDelete bool) Option {
+	return func(c *Connector) {
+		c.permanentDelete = permanentDelete
+	}
+}
+
+// WithDocumentTemplates sets the documents copied to create the documents of each product. Blank documents
+// are created for the products without a template.
+func WithDocumentTemplates(templates map[string]string) Option {
+	return func(c *Connector) {
+		c.documentTemplates = templates
+	}
+}
+
+// WithBaseUrl sends the requests to another Lucid API, like the FedRAMP one or a fake one in tests.
+func WithBaseUrl(baseUrl string) Option {
+	return func(c *Connector) {
+		c.baseUrl = baseUrl
+	}
+}
+
+// WithPageSize sets the number of items requested in each page from Lucid, so that large accounts sync in
+// fewer requests. It can't be more than client.MaxPageSize.
+func WithPageSize(pageSize int) Option {
+	return func(c *Connector) {
+		c.pageSize = pageSize
+	}
+}
+
+// WithPrefetch lists the folder tree ahead of the syncer, a number of folders at a time. The folders and
+// documents are then listed from the prefetched pages, which cuts the sync time of deep trees without
+// changing its output.
+func WithPrefetch(concurrency int) Option {
+	return func(c *Connector) {
+		c.prefetchConcurrency = concurrency
+	}
+}
+
+// WithCollaboratorLoader loads the collaborators of the documents ahead of their grants, a number of
+// documents at a time. The grants are the same, but folders with many documents sync much faster.
+func WithCollaboratorLoader(concurrency int) Option {
+	return func(c *Connector) {
+		c.collaboratorConcurrency = concurrency
+	}
+}
+
+// WithRequestsPerSecond caps the requests sent to Lucid, no limit when 0. The limit is shared by the syncer,
+// the prefetch, the collaborator loader and offboarding, which all send their requests through the client.
+func WithRequestsPerSecond(requestsPerSecond int) Option {
+	return func(c *Connector) {
+		c.requestsPerSecond = requestsPerSecond
+	}
+}
+
+// WithAuthTypes authorizes the requests of Lucid endpoints with another credential than their default one, e.g.
+// OAuth2 for the folders, documents and shares of an account preferring an account-level OAuth2 app.
+func WithAuthTypes(authTypes map[client.LucidEndpoint]client.LucidAuthType) Option {
+	return func(c *Connector) {
+		c.authTypes = authTypes
+	}
+}
+
+// WithCassette records the requests to Lucid into the cassette file, with the secrets and personal details
+// redacted, or replays them from it without any network access, to reproduce a sync.
+func WithCassette(mode client.CassetteMode, path string) Option {
+	return func(c *Connector) {
+		c.cassetteMode = mode
+		c.cassettePath = path
+	}
+}
+
+// WithShareLinkPolicy sets the policy enforced on share links created or updated through the connector.
+func WithShareLinkPolicy(policy ShareLinkPolicy) Option {
+	return func(c *Connector) {
+		c.shareLinkPolicy = &policy
+	}
 }
 
 // ResourceSyncers returns a ResourceSyncer for each resource type that should be synced from the upstream service.
 func (d *Connector) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
-	return []connectorbuilder.ResourceSyncer{
+	syncDocuments := d.documentSyncMode != DocumentSyncNone
+
+	syncers := []connectorbuilder.ResourceSyncer{
 		newUserBuilder(d.client),
-		newFolderBuilder(d.client),
-		newDocumentBuilder(d.client),
 	}
+
+	// Teams can only be listed with OAuth2 credentials.
+	if d.client.HasOAuth2() {
+		syncers = append(syncers, newTeamBuilder(d.client))
+	} else {
+		ctxzap.Extract(ctx).Info("baton-lucidchart: running with an API key only, teams and events are disabled")
+	}
+
+	syncers = append(syncers, newFolderBuilder(d.client, d.folderScope, d.trashedPolicy, d.permanentDelete, syncDocuments))
+
+	if syncDocuments {
+		for _, product := range []string{client.ProductLucidchart, client.ProductLucidspark, client.ProductLucidscale} {
+			syncers = append(
+				syncers,
+				newDocumentBuilder(
+					d.client,
+					product,
+					d.documentTemplates[product],
+					d.folderScope,
+					d.trashedPolicy,
+					d.permanentDelete,
+					d.documentSyncMode == DocumentSyncDirectShares,
+					d.collaborators,
+				),
+			)
+		}
+
+		syncers = append(syncers, newShareLinkBuilder(d.client, d.shareLinkPolicy))
+	}
+
+	return syncers
 }
 
 // Asset takes an input AssetRef and attempts to fetch it using the connector's authenticated http client
@@ -33,9 +212,14 @@ func (d *Connector) Asset(ctx context.Context, asset *v2.AssetRef) (string, io.R
 
 // Metadata returns metadata about the connector.
 func (d *Connector) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
+	description := "Lucidchart connector"
+	if !d.client.HasOAuth2() {
+		description = "Lucidchart connector, running with an API key only: teams and events are disabled"
+	}
+
 	return &v2.ConnectorMetadata{
 		DisplayName: "Lucidchart",
-		Description: "Lucidchart connector",
+		Description: description,
 	}, nil
 }
 
@@ -46,35 +230,126 @@ func (d *Connector) Validate(ctx context.Context) (annotations.Annotations, erro
 }
 
 // New returns a new instance of the connector.
-func New(ctx context.Context, apiKey, code, clientId, clientSecret, redirectUrl, refreshToken string) (*Connector, error) {
-	if apiKey == "" {
-		return nil, errors.New("apiKey is required")
+func New(ctx context.Context, apiKey, code, clientId, clientSecret, redirectUrl, refreshToken string, opts ...Option) (*Connector, error) {
+	// Without any OAuth2 credential, the connector runs with the API key only, and without an API key, it runs
+	// with an account-scoped OAuth2 token only.
+	var oauth2Opts *client.LucidChartOAuth2Options
+	if clientId != "" || clientSecret != "" || code != "" || refreshToken != "" {
+		if clientId == "" {
+			return nil, errors.New("clientId is required")
+		}
+
+		if clientSecret == "" {
+			return nil, errors.New("clientSecret is required")
+		}
+
+		if code == "" && refreshToken == "" {
+			return nil, errors.New("either code or refreshToken is required")
+		}
+
+		// The code is exchanged for a token against the redirect URL it was generated for.
+		if code != "" && redirectUrl == "" {
+			return nil, errors.New("redirectUrl is required with a code")
+		}
+
+		oauth2Opts = &client.LucidChartOAuth2Options{
+			Code:         code,
+			ClientID:     clientId,
+			ClientSecret: clientSecret,
+			RedirectUrl:  redirectUrl,
+			RefreshToken: refreshToken,
+		}
+	}
+
+	if apiKey == "" && oauth2Opts == nil {
+		return nil, errors.New("either apiKey or an OAuth2 client is required")
+	}
+
+	connector := &Connector{
+		folderScope:      newFolderScopeTracker(FolderScope{}),
+		documentSyncMode: DocumentSyncAll,
+		trashedPolicy:    TrashedContentInclude,
+	}
+
+	for _, opt := range opts {
+		opt(connector)
+	}
+
+	for endpoint, authType := range connector.authTypes {
+		if err := client.ValidateAuthType(endpoint, authType); err != nil {
+			return nil, err
+		}
+
+		if authType == client.LucidAuthTypeApiKey && apiKey == "" {
+			return nil, fmt.Errorf("the %s endpoint needs an API key", endpoint)
+		}
+
+		if authType == client.LucidAuthTypeOAuth2 && oauth2Opts == nil {
+			return nil, fmt.Errorf("the %s endpoint needs an OAuth2 client", endpoint)
+		}
+	}
+
+	if connector.pageSize > client.MaxPageSize {
+		return nil, fmt.Errorf("the page size can't be more than %d", client.MaxPageSize)
 	}
 
-	if clientId == "" {
-		return nil, errors.New("clientId is required")
+	var clientOpts []client.ClientOption
+	if connector.baseUrl != "" {
+		clientOpts = append(clientOpts, client.WithBaseUrl(client.ClientUrl(connector.baseUrl)))
 	}
 
-	if clientSecret == "" {
-		return nil, errors.New("clientSecret is required")
+	if connector.pageSize > 0 {
+		clientOpts = append(clientOpts, client.WithPageSize(connector.pageSize))
 	}
 
-	if redirectUrl == "" {
-		return nil, errors.New("redirectUrl is required")
+	if connector.prefetchConcurrency > 0 {
+		clientOpts = append(clientOpts, client.WithPrefetch(client.PrefetchOptions{
+			Concurrency: connector.prefetchConcurrency,
+			Walk:        connector.walkable,
+		}))
 	}
 
-	lucidClient, err := client.NewLucidchartClient(ctx, apiKey, &client.LucidChartOAuth2Options{
-		Code:         code,
-		ClientID:     clientId,
-		ClientSecret: clientSecret,
-		RedirectUrl:  redirectUrl,
-		RefreshToken: refreshToken,
-	})
+	if connector.requestsPerSecond > 0 {
+		clientOpts = append(clientOpts, client.WithRequestsPerSecond(connector.requestsPerSecond))
+	}
+
+	if len(connector.authTypes) > 0 {
+		clientOpts = append(clientOpts, client.WithAuthTypes(connector.authTypes))
+	}
+
+	if connector.cassetteMode != "" {
+		clientOpts = append(clientOpts, client.WithCassette(connector.cassetteMode, connector.cassettePath))
+	}
+
+	lucidClient, err := client.NewLucidchartClient(ctx, apiKey, oauth2Opts, clientOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Connector{
-		client: lucidClient,
-	}, nil
+	connector.client = lucidClient
+
+	if connector.collaboratorConcurrency > 0 {
+		connector.collaborators = newCollaboratorLoader(lucidClient, connector.collaboratorConcurrency)
+	}
+
+	return connector, nil
+}
+
+// walkable returns whether the prefetch lists a folder, which it mustn't when the sync skips it: folders out
+// of scope, and trashed folders when they are excluded, at the cost of fetching the folder.
+func (d *Connector) walkable(ctx context.Context, folder client.FolderContent, depth int) (bool, error) {
+	if !d.folderScope.walkable(folder, depth) {
+		return false, nil
+	}
+
+	if d.trashedPolicy != TrashedContentExclude {
+		return true, nil
+	}
+
+	f, err := d.client.GetFolder(ctx, folder.ID())
+	if err != nil {
+		return false, err
+	}
+
+	return f.Trashed == nil, nil
 }
@@ -0,0 +1,385 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+	"github.com/conductorone/baton-lucidchart/pkg/lucidtest"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+func testFixtures() lucidtest.Fixtures {
+	return lucidtest.Fixtures{
+		Users: []client.User{
+			{UserId: 1, Email: "jane@example.com", Name: "Jane"},
+			{UserId: 2, Email: "john@example.com", Name: "John"},
+		},
+		Teams: []client.Team{
+			{TeamId: 10, Name: "Engineering"},
+		},
+		Folders: map[string]client.Folder{
+			"100": {Id: 100, Type: client.FolderTypeFolder, Name: "Projects"},
+		},
+		Documents: map[string]client.Document{
+			"doc": {
+				DocumentId: "doc",
+				Title:      "Architecture",
+				Product:    client.ProductLucidchart,
+				Parent:     100,
+				Owner:      client.DocumentOwner{Id: 1, Type: client.OwnerTypeUser, Name: "Jane"},
+			},
+		},
+		FolderContents: map[string][]client.FolderContent{
+			"root": {
+				{Id: 100, Type: client.FolderTypeFolder, Name: "Projects"},
+			},
+			"100": {
+				{Id: "doc", Type: "document", Name: "Architecture", Product: client.ProductLucidchart},
+				{Id: "board", Type: "document", Name: "Retro", Product: client.ProductLucidspark},
+			},
+		},
+		FolderCollaborators: map[string][]client.FolderUserCollaboration{
+			"100": {
+				{FolderId: 100, UserId: 1, Role: "owner"},
+				{FolderId: 100, UserId: 2, Role: "view"},
+			},
+		},
+		DocumentCollaborators: map[string][]client.DocumentUserCollaboration{
+			"doc": {
+				{DocumentId: "doc", UserId: 1, Role: "owner"},
+				{DocumentId: "doc", UserId: 2, Role: "edit"},
+				{DocumentId: "doc", UserId: 3, Role: "admin"},
+			},
+		},
+		PageSize: 1,
+	}
+}
+
+func newTestConnector(t *testing.T, fixtures lucidtest.Fixtures, opts ...Option) (*Connector, *lucidtest.Server) {
+	t.Helper()
+
+	server := lucidtest.NewServer(fixtures)
+	t.Cleanup(server.Close)
+
+	opts = append(opts, WithBaseUrl(server.URL))
+
+	c, err := New(context.Background(), lucidtest.ApiKey, "", "client-id", "client-secret", "https://example.com", "refresh-token", opts...)
+	require.NoError(t, err)
+
+	return c, server
+}
+
+func listAll(t *testing.T, syncer interface {
+	List(context.Context, *v2.ResourceId, *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error)
+}, parentResourceID *v2.ResourceId) []*v2.Resource {
+	t.Helper()
+
+	var resources []*v2.Resource
+	token := ""
+	for {
+		page, nextToken, _, err := syncer.List(context.Background(), parentResourceID, &pagination.Token{Token: token})
+		require.NoError(t, err)
+
+		resources = append(resources, page...)
+		if nextToken == "" {
+			return resources
+		}
+
+		token = nextToken
+	}
+}
+
+func grantsAll(t *testing.T, syncer interface {
+	Grants(context.Context, *v2.Resource, *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error)
+}, resource *v2.Resource) []*v2.Grant {
+	t.Helper()
+
+	var grants []*v2.Grant
+	token := ""
+	for {
+		page, nextToken, _, err := syncer.Grants(context.Background(), resource, &pagination.Token{Token: token})
+		require.NoError(t, err)
+
+		grants = append(grants, page...)
+		if nextToken == "" {
+			return grants
+		}
+
+		token = nextToken
+	}
+}
+
+// createRequest returns a resource to create with Create, named name under parent, with the settings in a
+// structpb.Struct annotation. A nil id creates a new resource, and a nil parent keeps the current one.
+func createRequest(t *testing.T, id *v2.ResourceId, name string, parent *v2.ResourceId, settings map[string]interface{}) *v2.Resource {
+	t.Helper()
+
+	fields, err := structpb.NewStruct(settings)
+	require.NoError(t, err)
+
+	return &v2.Resource{Id: id, DisplayName: name, ParentResourceId: parent, Annotations: annotations.New(fields)}
+}
+
+func TestConnectorSyncAgainstFakeServer(t *testing.T) {
+	c, server := newTestConnector(t, testFixtures())
+
+	users := listAll(t, newUserBuilder(c.client), nil)
+	require.Len(t, users, 2)
+	require.Equal(t, 1, server.Count(http.MethodPost, "/oauth2/token"))
+
+	folders := newFolderBuilder(c.client, c.folderScope, c.trashedPolicy, false, true)
+
+	roots := listAll(t, folders, nil)
+	require.Len(t, roots, 1)
+
+	children := listAll(t, folders, roots[0].Id)
+	require.Len(t, children, 1)
+	require.Equal(t, "Projects", children[0].DisplayName)
+
+	folderGrants := grantsAll(t, folders, children[0])
+	require.Len(t, folderGrants, 2)
+
+	documents := newDocumentBuilder(c.client, client.ProductLucidchart, "", c.folderScope, c.trashedPolicy, false, false, nil)
+
+	// The Lucidspark board of the folder is synced by another builder.
+	docs := listAll(t, documents, children[0].Id)
+	require.Len(t, docs, 1)
+	require.Equal(t, "Architecture", docs[0].DisplayName)
+
+	// The owner is granted once, and the collaborator with an unknown role is skipped.
+	documentGrants := grantsAll(t, documents, docs[0])
+	require.Len(t, documentGrants, 2)
+	require.Equal(t, "document:doc:user/owner", documentGrants[0].Entitlement.Id)
+	require.Equal(t, "document:doc:user/edit", documentGrants[1].Entitlement.Id)
+
+	// The folder content was paginated, and requested once for both builders.
+	require.Equal(t, 2, server.Count(http.MethodGet, "/folders/100/contents"))
+}
+
+func TestConnectorProvisioningAgainstFakeServer(t *testing.T) {
+	c, server := newTestConnector(t, testFixtures())
+	ctx := context.Background()
+
+	folders := newFolderBuilder(c.client, c.folderScope, c.trashedPolicy, false, true)
+
+	folder, err := rs.NewResource("Projects", folderResourceType, "100")
+	require.NoError(t, err)
+
+	user, err := rs.NewResource("Jane", userResourceType, "1")
+	require.NoError(t, err)
+
+	entitlement := &v2.Entitlement{Resource: folder, Slug: folderHasUserAccessEntitlement + "edit"}
+
+	_, _, err = folders.Grant(ctx, user, entitlement)
+	require.NoError(t, err)
+
+	server.Inspect(func(fixtures *lucidtest.Fixtures) {
+		require.Equal(t, "edit", fixtures.FolderCollaborators["100"][0].Role)
+	})
+
+	grant := &v2.Grant{Entitlement: entitlement, Principal: user}
+
+	annos, err := folders.Revoke(ctx, grant)
+	require.NoError(t, err)
+	require.Empty(t, annos)
+
+	// Revoking again finds no collaborator.
+	annos, err = folders.Revoke(ctx, grant)
+	require.NoError(t, err)
+	require.True(t, annos.Contains(&v2.GrantAlreadyRevoked{}))
+
+	created, _, err := folders.Create(ctx, &v2.Resource{DisplayName: "Onboarding"})
+	require.NoError(t, err)
+	require.Equal(t, "Onboarding", created.DisplayName)
+	require.Equal(t, rootId, created.ParentResourceId.Resource)
+}
+
+func TestConnectorErrorsFromFakeServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized},
+		{name: "not found", status: http.StatusNotFound},
+		{name: "rate limited", status: http.StatusTooManyRequests},
+		{name: "server error", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, server := newTestConnector(t, testFixtures())
+			server.Fail(lucidtest.Failure{Method: http.MethodGet, Path: "/folders/*/shares/users", Status: tt.status})
+
+			folder, err := rs.NewResource("Projects", folderResourceType, "100")
+			require.NoError(t, err)
+
+			folders := newFolderBuilder(c.client, c.folderScope, c.trashedPolicy, false, true)
+			_, _, _, err = folders.Grants(context.Background(), folder, &pagination.Token{})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestConnectorPageSize(t *testing.T) {
+	c, server := newTestConnector(t, testFixtures(), WithPageSize(10))
+
+	folders := newFolderBuilder(c.client, c.folderScope, c.trashedPolicy, false, true)
+
+	folder, err := folderResource("100", "Projects", client.FolderTypeFolder, nil)
+	require.NoError(t, err)
+
+	// The fixtures' page of 1 item is overridden by the requested page size.
+	children := listAll(t, folders, folder.Id)
+	require.Empty(t, children)
+	require.Equal(t, 1, server.Count(http.MethodGet, "/folders/100/contents"))
+	require.Len(t, grantsAll(t, folders, folder), 2)
+	require.Equal(t, 1, server.Count(http.MethodGet, "/folders/100/shares/users"))
+
+	for _, r := range server.Requests() {
+		require.Equal(t, "10", r.Query.Get("pageSize"))
+	}
+
+	_, err = New(context.Background(), lucidtest.ApiKey, "", "", "", "", "", WithPageSize(client.MaxPageSize+1))
+	require.ErrorContains(t, err, "page size can't be more than 200")
+}
+
+func TestConnectorApiKeyOnly(t *testing.T) {
+	ctx := context.Background()
+
+	fixtures := testFixtures()
+	fixtures.Documents["doc"] = client.Document{
+		DocumentId: "doc",
+		Title:      "Architecture",
+		Product:    client.ProductLucidchart,
+		Parent:     100,
+		Owner:      client.DocumentOwner{Id: 10, Type: client.OwnerTypeTeam, Name: "Engineering"},
+	}
+
+	server := lucidtest.NewServer(fixtures)
+	t.Cleanup(server.Close)
+
+	c, err := New(ctx, lucidtest.ApiKey, "", "", "", "", "", WithBaseUrl(server.URL))
+	require.NoError(t, err)
+	require.False(t, c.client.HasOAuth2())
+
+	// Users are listed with the API key, and teams aren't synced.
+	users := listAll(t, newUserBuilder(c.client), nil)
+	require.Len(t, users, 2)
+	require.Zero(t, server.Count(http.MethodPost, "/oauth2/token"))
+
+	for _, syncer := range c.ResourceSyncers(ctx) {
+		require.NotEqual(t, teamResourceType, syncer.ResourceType(ctx))
+	}
+
+	_, _, err = c.client.ListTeams(ctx, "")
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, _, _, err = c.ListEvents(ctx, nil, &pagination.StreamToken{})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// The document owned by a team isn't granted to the team, which isn't synced.
+	document, err := rs.NewResource("Architecture", documentResourceTypeForProduct(client.ProductLucidchart), "doc")
+	require.NoError(t, err)
+
+	documents := newDocumentBuilder(c.client, client.ProductLucidchart, "", c.folderScope, c.trashedPolicy, false, false, nil)
+	for _, grant := range grantsAll(t, documents, document) {
+		require.Equal(t, userResourceType.Id, grant.Principal.Id.ResourceType)
+	}
+
+	metadata, err := c.Metadata(ctx)
+	require.NoError(t, err)
+	require.Contains(t, metadata.Description, "API key only")
+}
+
+func TestNewCredentials(t *testing.T) {
+	tests := []struct {
+		name                                                            string
+		apiKey, code, clientId, clientSecret, redirectUrl, refreshToken string
+		wantErr                                                         string
+	}{
+		{name: "API key only", apiKey: "key"},
+		{name: "refresh token", apiKey: "key", clientId: "id", clientSecret: "secret", refreshToken: "token"},
+		{name: "code", apiKey: "key", code: "code", clientId: "id", clientSecret: "secret", redirectUrl: "https://example.com"},
+		{name: "OAuth2 only", clientId: "id", clientSecret: "secret", refreshToken: "token"},
+		{name: "no credentials", wantErr: "either apiKey or an OAuth2 client is required"},
+		{name: "refresh token without client", apiKey: "key", refreshToken: "token", wantErr: "clientId is required"},
+		{name: "client without secret", apiKey: "key", clientId: "id", refreshToken: "token", wantErr: "clientSecret is required"},
+		{name: "client without token", apiKey: "key", clientId: "id", clientSecret: "secret", wantErr: "either code or refreshToken is required"},
+		{name: "code without redirect URL", apiKey: "key", code: "code", clientId: "id", clientSecret: "secret", wantErr: "redirectUrl is required with a code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(context.Background(), tt.apiKey, tt.code, tt.clientId, tt.clientSecret, tt.redirectUrl, tt.refreshToken)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewAuthTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		apiKey    string
+		clientId  string
+		authTypes map[client.LucidEndpoint]client.LucidAuthType
+		wantErr   string
+	}{
+		{
+			name:      "shares with OAuth2",
+			apiKey:    "key",
+			clientId:  "id",
+			authTypes: map[client.LucidEndpoint]client.LucidAuthType{client.LucidEndpointShares: client.LucidAuthTypeOAuth2},
+		},
+		{
+			name:      "folders with an API key without one",
+			clientId:  "id",
+			authTypes: map[client.LucidEndpoint]client.LucidAuthType{client.LucidEndpointFolders: client.LucidAuthTypeApiKey},
+			wantErr:   "the folders endpoint needs an API key",
+		},
+		{
+			name:      "users with OAuth2 without a client",
+			apiKey:    "key",
+			authTypes: map[client.LucidEndpoint]client.LucidAuthType{client.LucidEndpointUsers: client.LucidAuthTypeOAuth2},
+			wantErr:   "the users endpoint needs an OAuth2 client",
+		},
+		{
+			name:      "teams with an API key",
+			apiKey:    "key",
+			clientId:  "id",
+			authTypes: map[client.LucidEndpoint]client.LucidAuthType{client.LucidEndpointTeams: client.LucidAuthTypeApiKey},
+			wantErr:   "baton-lucidchart: the teams endpoint only accepts OAuth2 tokens, not an API key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clientSecret, refreshToken string
+			if tt.clientId != "" {
+				clientSecret, refreshToken = "secret", "token"
+			}
+
+			_, err := New(context.Background(), tt.apiKey, "", tt.clientId, clientSecret, "", refreshToken, WithAuthTypes(tt.authTypes))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
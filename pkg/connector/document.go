@@ -3,7 +3,10 @@ package connector
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -24,14 +27,46 @@ const (
 	rootId = "root"
 
 	documentHasUserAccessEntitlement = "user/"
+
+	documentOwnerRole = "owner"
 )
 
+// documentBuilder syncs the documents of a single Lucid product, each product having its own resource type.
 type documentBuilder struct {
-	client *client.LucidchartClient
+	client       *client.LucidchartClient
+	product      string
+	resourceType *v2.ResourceType
+	roles        roleCatalog
+	// templateId is the document copied to create documents, blank documents are created when it is empty.
+	templateId    string
+	scope         *folderScopeTracker
+	trashedPolicy TrashedContentPolicy
+	trash         *trashManager
+
+	// directSharesOnly skips the documents shared exactly like their parent folder.
+	directSharesOnly bool
+	// collaborators loads the collaborators of the documents ahead of their grants, nil when disabled.
+	collaborators *collaboratorLoader
+
+	// folderCollaborators are the roles of the collaborators of the folders, and sharedCollaborators the
+	// pages of collaborators the direct shares check listed for the documents it kept, until their grants
+	// are synced. Both are reset when a sync starts.
+	sharesMutex         sync.Mutex
+	folderCollaborators map[string]map[int]string
+	sharedCollaborators map[string][][]client.DocumentUserCollaboration
+	// owners are the owners of the documents the trash check fetched, until their grants are synced, so
+	// that each document is fetched once per sync. They are reset with the shares.
+	owners map[string]client.DocumentOwner
+	// listed are the documents listed by the sync in order, listedIndex their position, and granted the ones
+	// whose grants were synced, so that the collaborator loader loads the documents around the one being
+	// granted. They are only kept with the collaborator loader, and reset with the shares.
+	listed      []string
+	listedIndex map[string]int
+	granted     map[string]bool
 }
 
 func (o *documentBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
-	return documentResourceType
+	return o.resourceType
 }
 
 func (o *documentBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
@@ -39,10 +74,18 @@ func (o *documentBuilder) List(ctx context.Context, parentResourceID *v2.Resourc
 
 	if parentResourceID == nil && pToken.Token == "" {
 		l.Info("baton-lucidchart: ignoring first List call for root folder, only uses parentResourceID")
+
+		// The first call starts the sync of the documents, so the shares of a previous sync are dropped.
+		o.resetShares()
+
 		return nil, "", nil, nil
 	}
 
 	if parentResourceID != nil {
+		if !o.scope.included(parentResourceID.Resource) {
+			return nil, "", nil, nil
+		}
+
 		var folderContent []client.FolderContent
 		var nextToken string
 		var err error
@@ -59,11 +102,27 @@ func (o *documentBuilder) List(ctx context.Context, parentResourceID *v2.Resourc
 			}
 		}
 
+		folderContent = o.filterProduct(folderContent)
+
+		if o.directSharesOnly {
+			folderContent, err = o.filterDirectlyShared(ctx, parentResourceID.Resource, folderContent)
+			if err != nil {
+				return nil, "", nil, err
+			}
+		}
+
 		innerDocuments, err := documentResources(folderContent, parentResourceID)
 		if err != nil {
 			return nil, "", nil, err
 		}
 
+		innerDocuments, err = applyTrashedPolicy(ctx, o.trashedPolicy, innerDocuments, o.listedTrashedAt)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		o.recordListed(innerDocuments)
+
 		return innerDocuments, nextToken, nil, nil
 	}
 
@@ -74,13 +133,19 @@ func (o *documentBuilder) List(ctx context.Context, parentResourceID *v2.Resourc
 func (o *documentBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	var rv []*v2.Entitlement
 
-	for _, role := range client.UserFolderRoles {
+	for _, role := range o.roles {
+		grantableTo := []*v2.ResourceType{userResourceType}
+		// Documents can also be owned by a team.
+		if role.Name == documentOwnerRole {
+			grantableTo = append(grantableTo, teamResourceType)
+		}
+
 		assigmentOptions := []entitlement.EntitlementOption{
-			entitlement.WithGrantableTo(userResourceType),
-			entitlement.WithDescription(fmt.Sprintf("%s can %s on %s", userResourceType.DisplayName, role, resource.DisplayName)),
-			entitlement.WithDisplayName(fmt.Sprintf("%s is %s of %s", userResourceType.DisplayName, role, resource.DisplayName)),
+			entitlement.WithGrantableTo(grantableTo...),
+			entitlement.WithDescription(fmt.Sprintf("%s %s %s", userResourceType.DisplayName, role.Description, resource.DisplayName)),
+			entitlement.WithDisplayName(fmt.Sprintf("%s of %s", role.DisplayName, resource.DisplayName)),
 		}
-		rv = append(rv, entitlement.NewPermissionEntitlement(resource, documentHasUserAccessEntitlement+role, assigmentOptions...))
+		rv = append(rv, entitlement.NewPermissionEntitlement(resource, documentHasUserAccessEntitlement+role.Name, assigmentOptions...))
 	}
 
 	return rv, "", nil, nil
@@ -91,17 +156,114 @@ func (o *documentBuilder) Grants(ctx context.Context, resource *v2.Resource, pTo
 		return nil, "", nil, nil
 	}
 
+	var grants []*v2.Grant
+
+	// The owner is fetched once, on the first page of collaborators, unless the trash check already did.
+	var owner *client.DocumentOwner
+	if pToken.Token == "" {
+		o.loadAround(ctx, resource.Id.Resource)
+
+		documentOwner, ok := o.takeOwner(resource.Id.Resource)
+		if !ok {
+			document, err := o.client.GetDocument(ctx, resource.Id.Resource)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			documentOwner = document.Owner
+		}
+
+		ownerGrant, err := documentOwnerGrant(resource, documentOwner)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		// Teams aren't synced with an API key only, so a grant to a team would dangle.
+		if documentOwner.Type == client.OwnerTypeTeam && !o.client.HasOAuth2() {
+			ownerGrant = nil
+		}
+
+		if ownerGrant != nil {
+			owner = &documentOwner
+			grants = append(grants, ownerGrant)
+		}
+	}
+
+	// The loaded collaborators are all synced on the first page, each page as if it had been listed. The
+	// ones the direct shares check listed are reused first.
+	if pToken.Token == "" {
+		pages, ok := o.takeSharedCollaborators(resource.Id.Resource)
+
+		var err error
+		if !ok {
+			pages, ok, err = o.collaborators.take(ctx, resource.Id.Resource)
+			if err != nil {
+				return nil, "", nil, err
+			}
+		}
+
+		if ok {
+			for i, collaborators := range pages {
+				if i > 0 {
+					owner = nil
+				}
+
+				pageGrants, err := o.collaboratorGrants(ctx, resource, owner, collaborators)
+				if err != nil {
+					return nil, "", nil, err
+				}
+
+				grants = append(grants, pageGrants...)
+			}
+
+			return grants, "", nil, nil
+		}
+	}
+
 	collaborators, nextToken, err := o.client.ListDocumentUserCollaborators(ctx, resource.Id.Resource, pToken.Token)
 	if err != nil {
 		return nil, "", nil, err
 	}
 
+	pageGrants, err := o.collaboratorGrants(ctx, resource, owner, collaborators)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return append(grants, pageGrants...), nextToken, nil, nil
+}
+
+// collaboratorGrants returns the grants of a page of collaborators of the document, skipping the owner
+// when it is already granted.
+func (o *documentBuilder) collaboratorGrants(
+	ctx context.Context,
+	resource *v2.Resource,
+	owner *client.DocumentOwner,
+	collaborators []client.DocumentUserCollaboration,
+) ([]*v2.Grant, error) {
+	l := ctxzap.Extract(ctx)
+
 	var grants []*v2.Grant
 
 	for _, collaborator := range collaborators {
+		if owner != nil && collaborator.Role == documentOwnerRole &&
+			owner.Type == client.OwnerTypeUser && owner.ID() == strconv.Itoa(collaborator.UserId) {
+			continue
+		}
+
+		// A grant for a role without an entitlement would dangle.
+		if !o.roles.has(collaborator.Role) {
+			l.Warn(
+				"baton-lucidchart: skipping document collaborator with unknown role",
+				zap.String("document_id", resource.Id.Resource),
+				zap.Int("user_id", collaborator.UserId),
+				zap.String("role", collaborator.Role),
+			)
+			continue
+		}
+
 		userID, err := rs.NewResourceID(userResourceType, collaborator.UserId)
 		if err != nil {
-			return nil, "", nil, err
+			return nil, err
 		}
 
 		metadata := map[string]interface{}{
@@ -114,7 +276,7 @@ func (o *documentBuilder) Grants(ctx context.Context, resource *v2.Resource, pTo
 		grants = append(grants, newGrant)
 	}
 
-	return grants, nextToken, nil, nil
+	return grants, nil
 }
 
 func (o *documentBuilder) Grant(ctx context.Context, resource *v2.Resource, entitlement *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
@@ -128,6 +290,13 @@ func (o *documentBuilder) Grant(ctx context.Context, resource *v2.Resource, enti
 		}
 
 		role := splitted[1]
+		if !o.roles.has(role) {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "baton-lucidchart: %s is not a role of document %s", role, documentId)
+		}
+
+		if role == documentOwnerRole {
+			return o.transferOwnership(ctx, entitlement.Resource, documentId, userId)
+		}
 
 		response, err := o.client.UpsertDocumentUserCollaborator(ctx, documentId, userId, role)
 		if err != nil {
@@ -153,6 +322,24 @@ func (o *documentBuilder) Grant(ctx context.Context, resource *v2.Resource, enti
 }
 
 func (o *documentBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	// Collaborators may have the owner role without owning the document, only the owner can't be revoked.
+	if grant.Entitlement.Slug == documentHasUserAccessEntitlement+documentOwnerRole {
+		document, err := o.client.GetDocument(ctx, grant.Entitlement.Resource.Id.Resource)
+		if err != nil {
+			return nil, err
+		}
+
+		ownerGrant, err := documentOwnerGrant(grant.Entitlement.Resource, document.Owner)
+		if err != nil {
+			return nil, err
+		}
+
+		if ownerGrant != nil && ownerGrant.Principal.Id.ResourceType == grant.Principal.Id.ResourceType &&
+			ownerGrant.Principal.Id.Resource == grant.Principal.Id.Resource {
+			return nil, fmt.Errorf("the owner of document %s cannot be revoked, grant ownership to another user instead", grant.Entitlement.Resource.Id.Resource)
+		}
+	}
+
 	if grant.Principal.Id.ResourceType == userResourceType.Id {
 		userId := grant.Principal.Id.Resource
 		documentId := grant.Entitlement.Resource.Id.Resource
@@ -172,15 +359,471 @@ func (o *documentBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotati
 	return nil, fmt.Errorf("resource type %s is not supported", grant.Principal.Id.ResourceType)
 }
 
+// Create creates a document titled after the display name of the resource, under its parent folder or in
+// the root folder. The document is a copy of the configured template of the product, or a blank document
+// when there is none. Settings are read from a structpb.Struct annotation: template names the template to
+// copy, which must be the configured one as other documents can't be copied, blank skips it, and the initial
+// owner and collaborators are described in collaboratorSettings. When the resource has the id of an existing
+// document, the collaborators are added to it. A trashed document is only changed with the restore setting,
+// which restores it first.
+func (o *documentBuilder) Create(ctx context.Context, resource *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	settings, others, err := parseCollaboratorSettings(resource.Annotations, o.roles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	restore, err := parseRestoreSetting(others)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	templateId, err := o.parseTemplateSettings(others)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var documentId string
+	if resource.Id != nil && resource.Id.Resource != "" {
+		documentId = resource.Id.Resource
+
+		err = o.trash.Restore(ctx, resource.Id, restore)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if restore {
+		return nil, nil, status.Error(codes.InvalidArgument, "baton-lucidchart: restore needs the id of the trashed document")
+	} else {
+		documentId, err = o.create(ctx, resource, templateId)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, userId := range sortedIds(settings.Collaborators) {
+		_, err = o.client.UpsertDocumentUserCollaborator(ctx, documentId, userId, settings.Collaborators[userId])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, groupId := range sortedIds(settings.Groups) {
+		_, err = o.client.UpsertDocumentGroupCollaborator(ctx, documentId, groupId, settings.Groups[groupId])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The ownership is transferred last, the API key may not be able to share the document afterwards.
+	if settings.Owner != "" {
+		_, err = o.client.TransferDocumentOwnership(ctx, documentId, settings.Owner)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	document, err := o.client.GetDocument(ctx, documentId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newResource, err := documentResourceFromDocument(document)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newResource, nil, nil
+}
+
+func (o *documentBuilder) create(ctx context.Context, resource *v2.Resource, templateId string) (string, error) {
+	if resource.DisplayName == "" {
+		return "", status.Error(codes.InvalidArgument, "baton-lucidchart: a document needs a title")
+	}
+
+	parentId := rootId
+	if resource.ParentResourceId != nil {
+		if resource.ParentResourceId.ResourceType != folderResourceType.Id {
+			return "", status.Error(codes.InvalidArgument, "baton-lucidchart: documents can only be created in a folder")
+		}
+
+		parentId = resource.ParentResourceId.Resource
+	}
+
+	// Lucidscale models are built from the data of an account, they can't be blank.
+	if templateId == "" && o.product == client.ProductLucidscale {
+		return "", status.Error(codes.InvalidArgument, "baton-lucidchart: Lucidscale models can only be created from a template")
+	}
+
+	document, err := o.client.CreateDocument(ctx, resource.DisplayName, o.product, parentId, templateId)
+	if err != nil {
+		return "", err
+	}
+
+	return document.DocumentId, nil
+}
+
+// parseTemplateSettings returns the template to copy, given the template and blank settings. Only the
+// configured template can be copied, so that creating a document can't copy any document of the account.
+func (o *documentBuilder) parseTemplateSettings(settings map[string]interface{}) (string, error) {
+	templateId := o.templateId
+
+	if value, ok := settings["template"]; ok {
+		template, ok := value.(string)
+		if !ok {
+			return "", invalidSetting("template", value)
+		}
+		if template != o.templateId {
+			return "", status.Errorf(
+				codes.InvalidArgument,
+				"baton-lucidchart: %s is not the configured template of %s documents",
+				template,
+				o.product,
+			)
+		}
+		delete(settings, "template")
+	}
+
+	if value, ok := settings["blank"]; ok {
+		blank, ok := value.(bool)
+		if !ok {
+			return "", invalidSetting("blank", value)
+		}
+		if blank {
+			templateId = ""
+		}
+		delete(settings, "blank")
+	}
+
+	err := rejectUnknownSettings("document", settings)
+	if err != nil {
+		return "", err
+	}
+
+	return templateId, nil
+}
+
+// Delete trashes the document, and deletes it permanently when the connector is configured to.
+func (o *documentBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	return o.trash.Delete(ctx, resourceId)
+}
+
+func (o *documentBuilder) trashedAt(ctx context.Context, documentId string) (*time.Time, error) {
+	document, err := o.client.GetDocument(ctx, documentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return document.Trashed, nil
+}
+
+// listedTrashedAt is trashedAt for the trash check of List, which keeps the owner of the documents it
+// doesn't drop for their grants.
+func (o *documentBuilder) listedTrashedAt(ctx context.Context, documentId string) (*time.Time, error) {
+	document, err := o.client.GetDocument(ctx, documentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if document.Trashed == nil || o.trashedPolicy != TrashedContentExclude {
+		o.sharesMutex.Lock()
+		o.owners[documentId] = document.Owner
+		o.sharesMutex.Unlock()
+	}
+
+	return document.Trashed, nil
+}
+
+// filterProduct drops the documents of the other products.
+func (o *documentBuilder) filterProduct(folderContent []client.FolderContent) []client.FolderContent {
+	var rv []client.FolderContent
+
+	for _, content := range folderContent {
+		if content.Type == "document" && documentResourceTypeForProduct(content.Product) != o.resourceType {
+			continue
+		}
+
+		rv = append(rv, content)
+	}
+
+	return rv
+}
+
+// filterDirectlyShared drops the documents that have no collaborator besides the ones of their parent folder.
+func (o *documentBuilder) filterDirectlyShared(ctx context.Context, folderId string, folderContent []client.FolderContent) ([]client.FolderContent, error) {
+	var rv []client.FolderContent
+
+	for _, content := range folderContent {
+		if content.Type != "document" {
+			rv = append(rv, content)
+			continue
+		}
+
+		shared, pages, err := o.hasDirectShares(ctx, folderId, content.ID())
+		if err != nil {
+			return nil, err
+		}
+
+		if shared {
+			o.keepSharedCollaborators(content.ID(), pages)
+			rv = append(rv, content)
+		}
+	}
+
+	return rv, nil
+}
+
+// hasDirectShares returns whether a collaborator of the document has a role that isn't covered by their
+// role on the parent folder, and every page of collaborators of the document. The pages are listed whole
+// so that Grants reuses them, each kept document then costing a single listing of its collaborators.
+func (o *documentBuilder) hasDirectShares(ctx context.Context, folderId, documentId string) (bool, [][]client.DocumentUserCollaboration, error) {
+	inherited, err := o.listFolderCollaborators(ctx, folderId)
+	if err != nil {
+		return false, nil, err
+	}
+
+	hasDirectShares := false
+
+	var pages [][]client.DocumentUserCollaboration
+
+	err = client.EachPage(
+		ctx,
+		func(ctx context.Context, pageToken string) ([]client.DocumentUserCollaboration, string, error) {
+			return o.client.ListDocumentUserCollaborators(ctx, documentId, pageToken)
+		},
+		func(collaborators []client.DocumentUserCollaboration) (bool, error) {
+			pages = append(pages, collaborators)
+
+			for _, collaborator := range collaborators {
+				// Ownership isn't a share.
+				if collaborator.Role == documentOwnerRole {
+					continue
+				}
+
+				if role, ok := inherited[collaborator.UserId]; !ok || !o.roles.covers(role, collaborator.Role) {
+					hasDirectShares = true
+				}
+			}
+
+			return true, nil
+		},
+	)
+	if err != nil {
+		return false, nil, err
+	}
+
+	return hasDirectShares, pages, nil
+}
+
+// keepSharedCollaborators keeps the pages of collaborators of a document kept by the direct shares check
+// until its grants are synced, unless defaultCollaboratorLoaderMaxPending documents are already kept, Grants
+// then listing them again.
+func (o *documentBuilder) keepSharedCollaborators(documentId string, pages [][]client.DocumentUserCollaboration) {
+	o.sharesMutex.Lock()
+	defer o.sharesMutex.Unlock()
+
+	if len(o.sharedCollaborators) >= defaultCollaboratorLoaderMaxPending {
+		return
+	}
+
+	o.sharedCollaborators[documentId] = pages
+}
+
+// takeSharedCollaborators returns the kept pages of collaborators of a document, and false when there are none.
+func (o *documentBuilder) takeSharedCollaborators(documentId string) ([][]client.DocumentUserCollaboration, bool) {
+	o.sharesMutex.Lock()
+	defer o.sharesMutex.Unlock()
+
+	pages, ok := o.sharedCollaborators[documentId]
+	delete(o.sharedCollaborators, documentId)
+
+	return pages, ok
+}
+
+// recordListed records the order of the listed documents for the collaborator loader.
+func (o *documentBuilder) recordListed(documents []*v2.Resource) {
+	if o.collaborators == nil {
+		return
+	}
+
+	o.sharesMutex.Lock()
+	defer o.sharesMutex.Unlock()
+
+	for _, document := range documents {
+		if _, ok := o.listedIndex[document.Id.Resource]; ok {
+			continue
+		}
+
+		o.listedIndex[document.Id.Resource] = len(o.listed)
+		o.listed = append(o.listed, document.Id.Resource)
+	}
+}
+
+// loadAround starts loading the collaborators of the documents listed around a document whose grants are
+// being synced. The syncer grants the documents in batches of their listing order, walking each batch
+// backwards, so the documents on both sides are loaded, except the ones already granted and the ones whose
+// collaborators the direct shares check kept.
+func (o *documentBuilder) loadAround(ctx context.Context, documentId string) {
+	if o.collaborators == nil {
+		return
+	}
+
+	o.sharesMutex.Lock()
+
+	o.granted[documentId] = true
+
+	index, ok := o.listedIndex[documentId]
+	if !ok {
+		o.sharesMutex.Unlock()
+		return
+	}
+
+	window := o.collaborators.window()
+
+	var documentIds []string
+	for i := max(index-window, 0); i <= min(index+window, len(o.listed)-1); i++ {
+		id := o.listed[i]
+		if o.granted[id] {
+			continue
+		}
+
+		if _, ok := o.sharedCollaborators[id]; ok {
+			continue
+		}
+
+		documentIds = append(documentIds, id)
+	}
+
+	o.sharesMutex.Unlock()
+
+	o.collaborators.enqueue(ctx, documentIds)
+}
+
+// takeOwner returns the owner of a document kept by the trash check, and false when there is none.
+func (o *documentBuilder) takeOwner(documentId string) (client.DocumentOwner, bool) {
+	o.sharesMutex.Lock()
+	defer o.sharesMutex.Unlock()
+
+	owner, ok := o.owners[documentId]
+	delete(o.owners, documentId)
+
+	return owner, ok
+}
+
+// resetShares drops the collaborators, owners and order of the folders and documents listed by a previous sync.
+func (o *documentBuilder) resetShares() {
+	o.sharesMutex.Lock()
+	defer o.sharesMutex.Unlock()
+
+	o.folderCollaborators = make(map[string]map[int]string)
+	o.sharedCollaborators = make(map[string][][]client.DocumentUserCollaboration)
+	o.owners = make(map[string]client.DocumentOwner)
+	o.listed = nil
+	o.listedIndex = make(map[string]int)
+	o.granted = make(map[string]bool)
+}
+
+// listFolderCollaborators returns the role of each collaborator of the folder, fetching them only once per
+// folder. The lock isn't held while listing, so that the shares of other documents are kept meanwhile.
+func (o *documentBuilder) listFolderCollaborators(ctx context.Context, folderId string) (map[int]string, error) {
+	o.sharesMutex.Lock()
+	collaborators, ok := o.folderCollaborators[folderId]
+	o.sharesMutex.Unlock()
+
+	if ok {
+		return collaborators, nil
+	}
+
+	roles := make(map[int]string)
+
+	// The root folder isn't shared.
+	if folderId != rootId {
+		err := client.EachPage(
+			ctx,
+			func(ctx context.Context, pageToken string) ([]client.FolderUserCollaboration, string, error) {
+				return o.client.ListFolderUserCollaborators(ctx, folderId, pageToken)
+			},
+			func(collaborators []client.FolderUserCollaboration) (bool, error) {
+				for _, collaborator := range collaborators {
+					roles[collaborator.UserId] = collaborator.Role
+				}
+
+				return true, nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	o.sharesMutex.Lock()
+	o.folderCollaborators[folderId] = roles
+	o.sharesMutex.Unlock()
+
+	return roles, nil
+}
+
+// transferOwnership changes the owner of the document through Lucid's ownership API, instead of
+// upserting an owner collaborator.
+func (o *documentBuilder) transferOwnership(ctx context.Context, document *v2.Resource, documentId, userId string) ([]*v2.Grant, annotations.Annotations, error) {
+	response, err := o.client.TransferDocumentOwnership(ctx, documentId, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ownerGrant, err := documentOwnerGrant(document, response.Owner)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ownerGrant == nil {
+		return nil, nil, nil
+	}
+
+	return []*v2.Grant{ownerGrant}, nil, nil
+}
+
+// documentOwnerGrant returns the grant of the owner entitlement for the owner of the document, which
+// can be either a user or a team. It returns nil when the document has no known owner.
+func documentOwnerGrant(resource *v2.Resource, owner client.DocumentOwner) (*v2.Grant, error) {
+	var principalType *v2.ResourceType
+
+	switch owner.Type {
+	case client.OwnerTypeUser:
+		principalType = userResourceType
+	case client.OwnerTypeTeam:
+		principalType = teamResourceType
+	default:
+		return nil, nil
+	}
+
+	ownerId := owner.ID()
+	if ownerId == "" {
+		return nil, nil
+	}
+
+	principalID, err := rs.NewResourceID(principalType, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{
+		"role":       documentOwnerRole,
+		"owner_type": owner.Type,
+		"owner_name": owner.Name,
+	}
+
+	return grant.NewGrant(resource, documentHasUserAccessEntitlement+documentOwnerRole, principalID, grant.WithGrantMetadata(metadata)), nil
+}
+
 func documentResources(folderContent []client.FolderContent, parentResourceID *v2.ResourceId) ([]*v2.Resource, error) {
 	var resources []*v2.Resource
 
 	for _, folder := range folderContent {
-		if folder.Type != "document" {
+		// Shortcuts point to documents synced under their real parent.
+		if folder.Type != "document" || folder.Shortcut {
 			continue
 		}
 
-		newResource, err := documentResource(folder.ID(), folder.Name, parentResourceID)
+		newResource, err := documentResource(folder.ID(), folder.Name, documentResourceTypeForProduct(folder.Product), parentResourceID)
 		if err != nil {
 			return nil, err
 		}
@@ -191,21 +834,70 @@ func documentResources(folderContent []client.FolderContent, parentResourceID *v
 	return resources, nil
 }
 
-func documentResource(id, name string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+// documentResourceFromDocument returns the resource of a document fetched from the documents API.
+func documentResourceFromDocument(document *client.Document) (*v2.Resource, error) {
+	parentId := rootId
+	if document.Parent != 0 {
+		parentId = strconv.Itoa(document.Parent)
+	}
+
+	parentResourceID, err := rs.NewResourceID(folderResourceType, parentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return documentResource(document.DocumentId, document.Title, documentResourceTypeForProduct(document.Product), parentResourceID)
+}
+
+func documentResource(id, name string, resourceType *v2.ResourceType, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
 	resourceOptions := []rs.ResourceOption{
 		rs.WithParentResourceID(parentResourceID),
+		rs.WithAnnotation(
+			&v2.ChildResourceType{
+				ResourceTypeId: shareLinkResourceType.Id,
+			},
+		),
 	}
 
 	return rs.NewResource(
 		name,
-		documentResourceType,
+		resourceType,
 		id,
 		resourceOptions...,
 	)
 }
 
-func newDocumentBuilder(client *client.LucidchartClient) *documentBuilder {
-	return &documentBuilder{
-		client: client,
+func newDocumentBuilder(
+	client *client.LucidchartClient,
+	product string,
+	templateId string,
+	scope *folderScopeTracker,
+	trashedPolicy TrashedContentPolicy,
+	permanentDelete bool,
+	directSharesOnly bool,
+	collaborators *collaboratorLoader,
+) *documentBuilder {
+	builder := &documentBuilder{
+		client:           client,
+		product:          product,
+		resourceType:     documentResourceTypeForProduct(product),
+		roles:            documentRolesForProduct(product),
+		templateId:       templateId,
+		scope:            scope,
+		trashedPolicy:    trashedPolicy,
+		directSharesOnly: directSharesOnly,
+		collaborators:    collaborators,
 	}
+	builder.resetShares()
+
+	builder.trash = &trashManager{
+		resourceType: builder.resourceType,
+		trashedAt:    builder.trashedAt,
+		trash:        client.TrashDocument,
+		restore:      client.RestoreDocument,
+		delete:       client.DeleteDocument,
+		permanent:    permanentDelete,
+	}
+
+	return builder
 }
@@ -0,0 +1,193 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+	"github.com/conductorone/baton-lucidchart/pkg/lucidtest"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+func TestDocumentBuilderFilterProduct(t *testing.T) {
+	content := []client.FolderContent{
+		folderContent("folder", "Folder"),
+		{Id: "chart", Type: "document", Name: "Chart", Product: client.ProductLucidchart},
+		{Id: "board", Type: "document", Name: "Board", Product: client.ProductLucidspark},
+		{Id: "model", Type: "document", Name: "Model", Product: client.ProductLucidscale},
+		{Id: "unknown", Type: "document", Name: "Unknown", Product: "lucidsomething"},
+	}
+
+	tests := []struct {
+		product string
+		want    []string
+	}{
+		{product: client.ProductLucidchart, want: []string{"folder", "chart", "unknown"}},
+		{product: client.ProductLucidspark, want: []string{"folder", "board"}},
+		{product: client.ProductLucidscale, want: []string{"folder", "model"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.product, func(t *testing.T) {
+			builder := newDocumentBuilder(nil, tt.product, "", nil, TrashedContentInclude, false, false, nil)
+
+			var ids []string
+			for _, c := range builder.filterProduct(content) {
+				ids = append(ids, c.ID())
+			}
+
+			require.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestDocumentBuilderParseTemplateSettings(t *testing.T) {
+	builder := newDocumentBuilder(nil, client.ProductLucidchart, "configured", nil, TrashedContentInclude, false, false, nil)
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		want     string
+		wantErr  bool
+	}{
+		{name: "configured template", settings: map[string]interface{}{}, want: "configured"},
+		{name: "configured template setting", settings: map[string]interface{}{"template": "configured"}, want: "configured"},
+		{name: "other template", settings: map[string]interface{}{"template": "other"}, wantErr: true},
+		{name: "blank document", settings: map[string]interface{}{"blank": true}, want: ""},
+		{name: "unknown setting", settings: map[string]interface{}{"color": "red"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateId, err := builder.parseTemplateSettings(tt.settings)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, templateId)
+		})
+	}
+}
+
+func TestDocumentBuilderRevokeOwner(t *testing.T) {
+	fixtures := testFixtures()
+	fixtures.DocumentCollaborators["doc"][1].Role = documentOwnerRole
+
+	c, server := newTestConnector(t, fixtures)
+	ctx := context.Background()
+
+	documents := newDocumentBuilder(c.client, client.ProductLucidchart, "", c.folderScope, c.trashedPolicy, false, false, nil)
+
+	document, err := rs.NewResource("Architecture", documentResourceType, "doc")
+	require.NoError(t, err)
+
+	entitlement := &v2.Entitlement{Resource: document, Slug: documentHasUserAccessEntitlement + documentOwnerRole}
+
+	// The owner of the document can only change by a transfer.
+	owner, err := rs.NewResourceID(userResourceType, "1")
+	require.NoError(t, err)
+
+	_, err = documents.Revoke(ctx, &v2.Grant{Entitlement: entitlement, Principal: &v2.Resource{Id: owner}})
+	require.ErrorContains(t, err, "cannot be revoked")
+
+	// Another collaborator with the owner role is revoked like any collaborator.
+	coOwner, err := rs.NewResourceID(userResourceType, "2")
+	require.NoError(t, err)
+
+	_, err = documents.Revoke(ctx, &v2.Grant{Entitlement: entitlement, Principal: &v2.Resource{Id: coOwner}})
+	require.NoError(t, err)
+
+	server.Inspect(func(fixtures *lucidtest.Fixtures) {
+		for _, collaborator := range fixtures.DocumentCollaborators["doc"] {
+			require.NotEqual(t, 2, collaborator.UserId)
+		}
+	})
+
+	// The owner is read again after a transfer, the previous owner is then revoked like any collaborator.
+	_, _, err = documents.Grant(ctx, &v2.Resource{Id: coOwner}, entitlement)
+	require.NoError(t, err)
+
+	_, err = documents.Revoke(ctx, &v2.Grant{Entitlement: entitlement, Principal: &v2.Resource{Id: owner}})
+	require.NoError(t, err)
+}
+
+func TestDocumentBuilderOwnerFromTrashCheck(t *testing.T) {
+	c, server := newTestConnector(t, testFixtures(), WithTrashedContentPolicy(TrashedContentMark))
+
+	folder, err := rs.NewResourceID(folderResourceType, "100")
+	require.NoError(t, err)
+
+	documents := newDocumentBuilder(c.client, client.ProductLucidchart, "", c.folderScope, c.trashedPolicy, false, false, nil)
+
+	for sync := 1; sync <= 2; sync++ {
+		require.Empty(t, listAll(t, documents, nil))
+
+		docs := listAll(t, documents, folder)
+		require.Len(t, docs, 1)
+
+		// The grants reuse the owner fetched by the trash check, which fetches it again in the next sync.
+		grants := grantsAll(t, documents, docs[0])
+		require.Equal(t, "1", grants[0].Principal.Id.Resource)
+		require.Equal(t, sync, server.Count(http.MethodGet, "/documents/doc"))
+	}
+
+	// Without the trash check, the grants fetch the owner.
+	document, err := rs.NewResource("Architecture", documentResourceType, "doc")
+	require.NoError(t, err)
+
+	grantsAll(t, documents, document)
+	require.Equal(t, 3, server.Count(http.MethodGet, "/documents/doc"))
+}
+
+func TestDocumentBuilderOwnerEntitlement(t *testing.T) {
+	documents := newDocumentBuilder(nil, client.ProductLucidchart, "", nil, TrashedContentInclude, false, false, nil)
+
+	document, err := rs.NewResource("Architecture", documentResourceType, "doc")
+	require.NoError(t, err)
+
+	entitlements, _, _, err := documents.Entitlements(context.Background(), document, &pagination.Token{})
+	require.NoError(t, err)
+
+	require.Equal(t, "Owner of Architecture", entitlements[0].DisplayName)
+	require.Equal(t, "User owns Architecture", entitlements[0].Description)
+}
+
+func TestDocumentBuilderDirectShares(t *testing.T) {
+	fixtures := testFixtures()
+	fixtures.FolderContents["100"] = append(fixtures.FolderContents["100"],
+		client.FolderContent{Id: "plain", Type: "document", Name: "Plain", Product: client.ProductLucidchart})
+	fixtures.DocumentCollaborators["plain"] = []client.DocumentUserCollaboration{
+		{DocumentId: "plain", UserId: 1, Role: "owner"},
+		{DocumentId: "plain", UserId: 2, Role: "view"},
+	}
+
+	c, server := newTestConnector(t, fixtures)
+
+	folder, err := rs.NewResourceID(folderResourceType, "100")
+	require.NoError(t, err)
+
+	documents := newDocumentBuilder(c.client, client.ProductLucidchart, "", c.folderScope, c.trashedPolicy, false, true, nil)
+
+	for sync := 1; sync <= 2; sync++ {
+		require.Empty(t, listAll(t, documents, nil))
+
+		// The document shared like its folder is skipped.
+		docs := listAll(t, documents, folder)
+		require.Len(t, docs, 1)
+		require.Equal(t, "doc", docs[0].Id.Resource)
+
+		// The grants reuse the collaborators listed by the check, a page per collaborator.
+		require.Len(t, grantsAll(t, documents, docs[0]), 2)
+		require.Equal(t, 3*sync, server.Count(http.MethodGet, "/documents/doc/shares/users"))
+
+		// The collaborators of the folder are listed again by the next sync.
+		require.Equal(t, 2*sync, server.Count(http.MethodGet, "/folders/100/shares/users"))
+	}
+}
@@ -0,0 +1,93 @@
+package connector
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestAuditLogEvent(t *testing.T) {
+	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	collaborator := &client.AuditLogUser{UserId: 42, Email: "jane@example.com"}
+
+	t.Run("document share created", func(t *testing.T) {
+		event, err := auditLogEvent(client.AuditLogEvent{
+			EventId:      "1",
+			EventType:    client.AuditLogEventDocumentShareCreated,
+			Timestamp:    timestamp,
+			Target:       client.AuditLogTarget{Id: "doc-1", Type: "document", Name: "Diagram"},
+			Role:         "edit",
+			Collaborator: collaborator,
+		})
+		require.NoError(t, err)
+
+		grantEvent := event.GetGrantEvent()
+		require.NotNil(t, grantEvent)
+		require.Equal(t, "document:doc-1:user/edit", grantEvent.Grant.Entitlement.Id)
+		require.Equal(t, "42", grantEvent.Grant.Principal.Id.Resource)
+	})
+
+	t.Run("folder share deleted", func(t *testing.T) {
+		event, err := auditLogEvent(client.AuditLogEvent{
+			EventId:      "2",
+			EventType:    client.AuditLogEventFolderShareDeleted,
+			Timestamp:    timestamp,
+			Target:       client.AuditLogTarget{Id: float64(123), Type: "folder", Name: "Folder"},
+			Role:         "view",
+			Collaborator: collaborator,
+		})
+		require.NoError(t, err)
+
+		revokeEvent := event.GetRevokeEvent()
+		require.NotNil(t, revokeEvent)
+		require.Equal(t, "folder:123:user/view", revokeEvent.Entitlement.Id)
+		require.Equal(t, "42", revokeEvent.Principal.Id.Resource)
+	})
+
+	t.Run("document trashed", func(t *testing.T) {
+		event, err := auditLogEvent(client.AuditLogEvent{
+			EventId:   "3",
+			EventType: client.AuditLogEventDocumentTrashed,
+			Timestamp: timestamp,
+			Actor:     *collaborator,
+			Target:    client.AuditLogTarget{Id: "doc-1", Type: "document", Name: "Diagram"},
+		})
+		require.NoError(t, err)
+
+		usageEvent := event.GetUsageEvent()
+		require.NotNil(t, usageEvent)
+		require.Equal(t, "doc-1", usageEvent.TargetResource.Id.Resource)
+		require.Equal(t, "42", usageEvent.ActorResource.Id.Resource)
+	})
+
+	t.Run("untracked event", func(t *testing.T) {
+		event, err := auditLogEvent(client.AuditLogEvent{
+			EventId:   "4",
+			EventType: "userLoggedIn",
+			Timestamp: timestamp,
+		})
+		require.NoError(t, err)
+		require.Nil(t, event)
+	})
+}
+
+func TestListEventsPageSize(t *testing.T) {
+	c, server := newTestConnector(t, testFixtures())
+
+	_, _, _, err := c.ListEvents(context.Background(), nil, &pagination.StreamToken{Size: 1000})
+	require.NoError(t, err)
+
+	var sizes []string
+	for _, r := range server.Requests() {
+		if r.Path == client.ListAuditLogsPath {
+			sizes = append(sizes, r.Query.Get("pageSize"))
+		}
+	}
+	require.Equal(t, []string{strconv.Itoa(client.MaxPageSize)}, sizes)
+}
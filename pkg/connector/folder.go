@@ -3,10 +3,13 @@ package connector
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -25,7 +28,13 @@ const (
 )
 
 type folderBuilder struct {
-	client *client.LucidchartClient
+	client        *client.LucidchartClient
+	scope         *folderScopeTracker
+	trashedPolicy TrashedContentPolicy
+	trash         *trashManager
+
+	// childResourceTypes are the resource types listed inside of folders, documents are not always synced.
+	childResourceTypes []*v2.ResourceType
 }
 
 func (o *folderBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -37,7 +46,11 @@ func (o *folderBuilder) List(ctx context.Context, parentResourceID *v2.ResourceI
 
 	// Root folder
 	if parentResourceID == nil && pToken.Token == "" {
-		root, err := folderResource("root", "root", nil)
+		// The syncer lists the folders from the root one, which starts a sync. The prefetch starts ahead of it.
+		o.client.StartSync(ctx)
+		o.scope.reset()
+
+		root, err := folderResource("root", "root", client.FolderTypeFolder, nil, o.childResourceTypes...)
 		if err != nil {
 			return nil, "", nil, err
 		}
@@ -54,7 +67,14 @@ func (o *folderBuilder) List(ctx context.Context, parentResourceID *v2.ResourceI
 			return nil, "", nil, err
 		}
 
-		innerFolders, err := folderResources(folderContent, parentResourceID)
+		folderContent = o.scope.filterFolders(parentResourceID.Resource, folderContent)
+
+		innerFolders, err := folderResources(folderContent, parentResourceID, o.childResourceTypes...)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		innerFolders, err = applyTrashedPolicy(ctx, o.trashedPolicy, innerFolders, o.trashedAt)
 		if err != nil {
 			return nil, "", nil, err
 		}
@@ -66,26 +86,37 @@ func (o *folderBuilder) List(ctx context.Context, parentResourceID *v2.ResourceI
 
 	return nil, "", nil, nil
 }
-func (o *folderBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+func (o *folderBuilder) Entitlements(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	var rv []*v2.Entitlement
 
-	for _, role := range client.UserFolderRoles {
+	roles, err := o.roles(ctx, resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, role := range roles {
 		assigmentOptions := []entitlement.EntitlementOption{
 			entitlement.WithGrantableTo(userResourceType),
-			entitlement.WithDescription(fmt.Sprintf("%s can %s on %s", userResourceType.DisplayName, role, resource.DisplayName)),
-			entitlement.WithDisplayName(fmt.Sprintf("%s is %s of %s", userResourceType.DisplayName, role, resource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("%s %s %s", userResourceType.DisplayName, role.Description, resource.DisplayName)),
+			entitlement.WithDisplayName(fmt.Sprintf("%s of %s", role.DisplayName, resource.DisplayName)),
 		}
-		rv = append(rv, entitlement.NewPermissionEntitlement(resource, folderHasUserAccessEntitlement+role, assigmentOptions...))
+		rv = append(rv, entitlement.NewPermissionEntitlement(resource, folderHasUserAccessEntitlement+role.Name, assigmentOptions...))
 	}
 
 	return rv, "", nil, nil
 }
 
 func (o *folderBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
 	if resource.Id.Resource == "root" {
 		return nil, "", nil, nil
 	}
 
+	if !o.scope.included(resource.Id.Resource) {
+		return nil, "", nil, nil
+	}
+
 	collaborators, nextToken, err := o.client.ListFolderUserCollaborators(ctx, resource.Id.Resource, pToken.Token)
 	if err != nil {
 		return nil, "", nil, err
@@ -93,7 +124,23 @@ func (o *folderBuilder) Grants(ctx context.Context, resource *v2.Resource, pToke
 
 	var grants []*v2.Grant
 
+	roles, err := o.roles(ctx, resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
 	for _, collaborator := range collaborators {
+		// A grant for a role without an entitlement would dangle.
+		if !roles.has(collaborator.Role) {
+			l.Warn(
+				"baton-lucidchart: skipping folder collaborator with unknown role",
+				zap.String("folder_id", resource.Id.Resource),
+				zap.Int("user_id", collaborator.UserId),
+				zap.String("role", collaborator.Role),
+			)
+			continue
+		}
+
 		userID, err := rs.NewResourceID(userResourceType, collaborator.UserId)
 		if err != nil {
 			return nil, "", nil, err
@@ -122,7 +169,15 @@ func (o *folderBuilder) Grant(ctx context.Context, resource *v2.Resource, entitl
 			return nil, nil, fmt.Errorf("invalid entitlement slug %s", entitlement.Slug)
 		}
 
+		roles, err := o.roles(ctx, entitlement.Resource)
+		if err != nil {
+			return nil, nil, err
+		}
+
 		role := splitted[1]
+		if !roles.has(role) {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "baton-lucidchart: %s is not a role of folder %s", role, folderId)
+		}
 
 		response, err := o.client.UpsertFolderUserCollaborator(ctx, folderId, userId, role)
 		if err != nil {
@@ -166,15 +221,217 @@ func (o *folderBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotation
 	return nil, fmt.Errorf("resource type %s is not supported", grant.Principal.Id.ResourceType)
 }
 
-func folderResources(folderContent []client.FolderContent, parentResourceID *v2.ResourceId) ([]*v2.Resource, error) {
+// Create creates a folder named after the display name of the resource, under its parent folder or in the
+// root folder. The initial owner and collaborators are read from a structpb.Struct annotation, see
+// collaboratorSettings. When the resource has the id of an existing folder, the folder is renamed and moved to
+// match the resource, and the collaborators are added to it. A trashed folder is only changed with the restore
+// setting, which restores it first. A new folder is trashed again when its collaborators can't be added, so
+// that a retry doesn't leave a folder without its collaborators behind.
+func (o *folderBuilder) Create(ctx context.Context, resource *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	settings, others, err := parseCollaboratorSettings(resource.Annotations, folderRoles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	restore, err := parseRestoreSetting(others)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = rejectUnknownSettings("folder", others)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parentId := ""
+	if resource.ParentResourceId != nil {
+		if resource.ParentResourceId.ResourceType != folderResourceType.Id {
+			return nil, nil, status.Error(codes.InvalidArgument, "baton-lucidchart: folders can only be created in a folder")
+		}
+
+		parentId = resource.ParentResourceId.Resource
+	}
+
+	var folder *client.Folder
+	created := false
+	if resource.Id != nil && resource.Id.Resource != "" {
+		folder, err = o.update(ctx, resource.Id, resource.DisplayName, parentId, restore)
+	} else if restore {
+		err = status.Error(codes.InvalidArgument, "baton-lucidchart: restore needs the id of the trashed folder")
+	} else {
+		folder, err = o.create(ctx, resource.DisplayName, parentId)
+		created = true
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	folderId := strconv.Itoa(folder.Id)
+
+	err = o.addCollaborators(ctx, folderId, settings)
+	if err != nil {
+		if !created {
+			return nil, nil, err
+		}
+
+		trashErr := o.client.TrashFolder(ctx, folderId)
+		if trashErr != nil {
+			ctxzap.Extract(ctx).Error(
+				"baton-lucidchart: failed to trash a folder created without its collaborators",
+				zap.String("folder_id", folderId),
+				zap.Error(trashErr),
+			)
+		}
+
+		return nil, nil, err
+	}
+
+	newResource, err := o.folderResource(folder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newResource, nil, nil
+}
+
+// addCollaborators adds the initial owner and collaborators of the settings to the folder.
+func (o *folderBuilder) addCollaborators(ctx context.Context, folderId string, settings *collaboratorSettings) error {
+	if settings.Owner != "" {
+		_, err := o.client.UpsertFolderUserCollaborator(ctx, folderId, settings.Owner, ownerRole.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, userId := range sortedIds(settings.Collaborators) {
+		_, err := o.client.UpsertFolderUserCollaborator(ctx, folderId, userId, settings.Collaborators[userId])
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, groupId := range sortedIds(settings.Groups) {
+		_, err := o.client.UpsertFolderGroupCollaborator(ctx, folderId, groupId, settings.Groups[groupId])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *folderBuilder) create(ctx context.Context, name, parentId string) (*client.Folder, error) {
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "baton-lucidchart: a folder needs a name")
+	}
+
+	if parentId == "" {
+		parentId = rootId
+	}
+
+	return o.client.CreateFolder(ctx, name, parentId)
+}
+
+// update restores the folder when restore is set, then renames and moves it when its name or parent differ.
+// An empty name or parent id keeps the current one.
+func (o *folderBuilder) update(ctx context.Context, resourceId *v2.ResourceId, name, parentId string, restore bool) (*client.Folder, error) {
+	if resourceId.Resource == rootId {
+		return nil, status.Error(codes.InvalidArgument, "baton-lucidchart: the root folder can't be updated")
+	}
+
+	if parentId == resourceId.Resource {
+		return nil, status.Error(codes.InvalidArgument, "baton-lucidchart: a folder can't be moved into itself")
+	}
+
+	err := o.trash.Restore(ctx, resourceId, restore)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := o.client.GetFolder(ctx, resourceId.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	currentParentId := rootId
+	if folder.Parent != 0 {
+		currentParentId = strconv.Itoa(folder.Parent)
+	}
+
+	var newName, newParentId *string
+	if name != "" && name != folder.Name {
+		newName = &name
+	}
+	if parentId != "" && parentId != currentParentId {
+		newParentId = &parentId
+	}
+
+	if newName == nil && newParentId == nil {
+		return folder, nil
+	}
+
+	return o.client.UpdateFolder(ctx, resourceId.Resource, newName, newParentId)
+}
+
+// Delete trashes the folder, with its content, and deletes it permanently when the connector is configured to.
+func (o *folderBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	return o.trash.Delete(ctx, resourceId)
+}
+
+// roles returns the roles of a folder, given the type in the description of its resource. The type of the
+// folders whose resource doesn't describe it, as the ones of the audit logs, is fetched.
+func (o *folderBuilder) roles(ctx context.Context, resource *v2.Resource) (roleCatalog, error) {
+	folderType, ok := folderTypeFromDescription(resource.Description)
+	if !ok && resource.Id.Resource != rootId {
+		folder, err := o.client.GetFolder(ctx, resource.Id.Resource)
+		if err != nil {
+			return nil, err
+		}
+
+		folderType = folder.Type
+	}
+
+	if folderType == client.FolderTypeTeam {
+		return teamFolderRoles, nil
+	}
+
+	return folderRoles, nil
+}
+
+func (o *folderBuilder) trashedAt(ctx context.Context, folderId string) (*time.Time, error) {
+	folder, err := o.client.GetFolder(ctx, folderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return folder.Trashed, nil
+}
+
+// folderResource returns the resource of a folder fetched from the folders API.
+func (o *folderBuilder) folderResource(folder *client.Folder) (*v2.Resource, error) {
+	parentId := rootId
+	if folder.Parent != 0 {
+		parentId = strconv.Itoa(folder.Parent)
+	}
+
+	parentResourceID, err := rs.NewResourceID(folderResourceType, parentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return folderResource(strconv.Itoa(folder.Id), folder.Name, folder.Type, parentResourceID, o.childResourceTypes...)
+}
+
+func folderResources(folderContent []client.FolderContent, parentResourceID *v2.ResourceId, childResourceTypes ...*v2.ResourceType) ([]*v2.Resource, error) {
 	var resources []*v2.Resource
 
 	for _, folder := range folderContent {
-		if folder.Type != "folder" {
+		// Shortcuts point to folders synced under their real parent.
+		if !folder.IsFolder() || folder.Shortcut {
 			continue
 		}
 
-		newResource, err := folderResource(folder.ID(), folder.Name, parentResourceID)
+		newResource, err := folderResource(folder.ID(), folder.Name, folder.Type, parentResourceID, childResourceTypes...)
 		if err != nil {
 			return nil, err
 		}
@@ -185,17 +442,22 @@ func folderResources(folderContent []client.FolderContent, parentResourceID *v2.
 	return resources, nil
 }
 
-func folderResource(id, name string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+// folderResource returns the resource of a folder, with its type in its description, as team folders have
+// their own roles. An empty type leaves the description empty.
+func folderResource(id, name, folderType string, parentResourceID *v2.ResourceId, childResourceTypes ...*v2.ResourceType) (*v2.Resource, error) {
+	var childAnnotations []proto.Message
+	for _, childResourceType := range childResourceTypes {
+		childAnnotations = append(childAnnotations, &v2.ChildResourceType{
+			ResourceTypeId: childResourceType.Id,
+		})
+	}
+
 	resourceOptions := []rs.ResourceOption{
 		rs.WithParentResourceID(parentResourceID),
-		rs.WithAnnotation(
-			&v2.ChildResourceType{
-				ResourceTypeId: folderResourceType.Id,
-			},
-			&v2.ChildResourceType{
-				ResourceTypeId: documentResourceType.Id,
-			},
-		),
+		rs.WithAnnotation(childAnnotations...),
+	}
+	if description := folderDescription(folderType); description != "" {
+		resourceOptions = append(resourceOptions, rs.WithDescription(description))
 	}
 
 	return rs.NewResource(
@@ -206,8 +468,58 @@ func folderResource(id, name string, parentResourceID *v2.ResourceId) (*v2.Resou
 	)
 }
 
-func newFolderBuilder(client *client.LucidchartClient) *folderBuilder {
-	return &folderBuilder{
-		client: client,
+// folderDescriptions describe the types of folders in the description of their resource.
+var folderDescriptions = map[string]string{
+	client.FolderTypeFolder: "Lucid folder",
+	client.FolderTypeTeam:   "Lucid team folder",
+}
+
+// folderDescription returns the description of a folder of a type, empty when the type isn't known.
+func folderDescription(folderType string) string {
+	return folderDescriptions[folderType]
+}
+
+// folderTypeFromDescription returns the type of a folder given the description of its resource, which
+// may be followed by the trash state, and false when it doesn't describe the type.
+func folderTypeFromDescription(description string) (string, bool) {
+	description, _, _ = strings.Cut(description, descriptionSeparator)
+
+	for folderType, typeDescription := range folderDescriptions {
+		if description == typeDescription {
+			return folderType, true
+		}
 	}
+
+	return "", false
+}
+
+func newFolderBuilder(
+	client *client.LucidchartClient,
+	scope *folderScopeTracker,
+	trashedPolicy TrashedContentPolicy,
+	permanentDelete bool,
+	syncDocuments bool,
+) *folderBuilder {
+	childResourceTypes := []*v2.ResourceType{folderResourceType}
+	if syncDocuments {
+		childResourceTypes = append(childResourceTypes, productDocumentResourceTypes...)
+	}
+
+	builder := &folderBuilder{
+		client:             client,
+		scope:              scope,
+		trashedPolicy:      trashedPolicy,
+		childResourceTypes: childResourceTypes,
+	}
+
+	builder.trash = &trashManager{
+		resourceType: folderResourceType,
+		trashedAt:    builder.trashedAt,
+		trash:        client.TrashFolder,
+		restore:      client.RestoreFolder,
+		delete:       client.DeleteFolder,
+		permanent:    permanentDelete,
+	}
+
+	return builder
 }
@@ -0,0 +1,218 @@
+package connector
+
+import (
+	"path"
+	"slices"
+	"sync"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+)
+
+// FolderScope restricts the folders that are synced. The zero value syncs every folder.
+type FolderScope struct {
+	// IncludeIds and IncludeNames select the folder trees to sync. Names are matched with path.Match
+	// patterns. Folders above the included trees are still synced to reach them, but without their
+	// documents and grants. Everything is included when both are empty.
+	IncludeIds   []string
+	IncludeNames []string
+
+	// ExcludeIds and ExcludeNames select the folder trees that are never synced, even inside an included tree.
+	ExcludeIds   []string
+	ExcludeNames []string
+
+	// MaxDepth is the deepest level of folders synced, the folders of the root folder being at depth 1.
+	// Zero means no limit. A resumed sync doesn't know the depth of the folders below the ones it resumes
+	// from, so it doesn't limit them.
+	MaxDepth int
+}
+
+func (s *FolderScope) hasIncludes() bool {
+	return len(s.IncludeIds) > 0 || len(s.IncludeNames) > 0
+}
+
+func (s *FolderScope) isIncluded(id, name string) bool {
+	return slices.Contains(s.IncludeIds, id) || matchesAny(s.IncludeNames, name)
+}
+
+func (s *FolderScope) isExcluded(id, name string) bool {
+	return slices.Contains(s.ExcludeIds, id) || matchesAny(s.ExcludeNames, name)
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unknownDepth is the depth of the folders whose parent wasn't traversed by this process, and of the folders
+// below them. The depth limit doesn't apply to them.
+const unknownDepth = -1
+
+type folderState struct {
+	// parentId is empty for a folder recorded as the parent of another, without having been found itself.
+	parentId string
+	depth    int
+	included bool
+}
+
+// folderScopeTracker applies a FolderScope while the folder tree is traversed. It records the parent and
+// depth of each synced folder and whether it is inside an included tree, which the builders check before
+// making any request for a folder. Recording the parents also stops the traversal from visiting a folder
+// twice, so a malformed tree can't make it loop forever. The folders are recorded per traversal, each sync
+// resetting them, as folders moved between syncs are found under another parent.
+type folderScopeTracker struct {
+	scope FolderScope
+
+	mutex   sync.RWMutex
+	folders map[string]folderState
+}
+
+func newFolderScopeTracker(scope FolderScope) *folderScopeTracker {
+	return &folderScopeTracker{
+		scope:   scope,
+		folders: make(map[string]folderState),
+	}
+}
+
+// reset forgets the folders of the previous traversal, once a sync starts listing the folders from the root one.
+func (t *folderScopeTracker) reset() {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.folders = make(map[string]folderState)
+}
+
+// state returns the state of a folder. Folders that weren't traversed by this process, as happens when
+// a sync is resumed, are considered included at an unknown depth.
+func (t *folderScopeTracker) state(folderId string) (folderState, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.lookup(folderId)
+}
+
+// lookup is state for callers holding the mutex.
+func (t *folderScopeTracker) lookup(folderId string) (folderState, bool) {
+	if folderId == rootId {
+		return folderState{depth: 0, included: !t.scope.hasIncludes()}, true
+	}
+
+	state, ok := t.folders[folderId]
+
+	return state, ok
+}
+
+// visit records a folder found in the content of its parent and returns whether it must be synced.
+func (t *folderScopeTracker) visit(parentId string, folder client.FolderContent) bool {
+	if t == nil {
+		return true
+	}
+
+	id := folder.ID()
+
+	if t.scope.isExcluded(id, folder.Name) {
+		return false
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// The parent wasn't traversed by this process, as happens when a sync is resumed. It is recorded without
+	// its own parent, so that the folders below it are still checked for cycles.
+	parent, known := t.lookup(parentId)
+	if !known {
+		parent = folderState{depth: unknownDepth, included: true}
+		t.folders[parentId] = parent
+	}
+
+	state := folderState{
+		parentId: parentId,
+		depth:    unknownDepth,
+		included: parent.included || t.scope.isIncluded(id, folder.Name),
+	}
+	if parent.depth != unknownDepth {
+		state.depth = parent.depth + 1
+	}
+
+	if t.scope.MaxDepth > 0 && state.depth > t.scope.MaxDepth {
+		return false
+	}
+
+	// A folder already found under another parent is either a duplicate or a cycle in the tree. A folder
+	// recorded without its parent is found under it for the first time, unless the parent is below it.
+	if existing, ok := t.folders[id]; ok && existing.parentId != parentId {
+		if existing.parentId != "" || t.isBelow(parentId, id) {
+			return false
+		}
+	}
+
+	t.folders[id] = state
+
+	return true
+}
+
+// isBelow returns whether a folder is in the tree of another, following the recorded parents.
+func (t *folderScopeTracker) isBelow(folderId, ancestorId string) bool {
+	// A malformed chain of parents can't be longer than the recorded folders.
+	for i := 0; i <= len(t.folders) && folderId != "" && folderId != rootId; i++ {
+		if folderId == ancestorId {
+			return true
+		}
+
+		folderId = t.folders[folderId].parentId
+	}
+
+	return false
+}
+
+// filterFolders drops the folders of a folder content page that are out of scope or already visited, and
+// the shortcuts to folders, which would otherwise be visited before their target.
+func (t *folderScopeTracker) filterFolders(parentId string, folderContent []client.FolderContent) []client.FolderContent {
+	if t == nil {
+		return folderContent
+	}
+
+	var rv []client.FolderContent
+	for _, content := range folderContent {
+		if content.IsFolder() && (content.Shortcut || !t.visit(parentId, content)) {
+			continue
+		}
+
+		rv = append(rv, content)
+	}
+
+	return rv
+}
+
+// included returns whether the documents and grants of a folder must be synced.
+func (t *folderScopeTracker) included(folderId string) bool {
+	if t == nil {
+		return true
+	}
+
+	state, known := t.state(folderId)
+
+	return !known || state.included
+}
+
+// walkable returns whether a folder found at a depth can be in scope, without recording it. The folders it
+// rejects are never synced, the others are checked by visit once the syncer reaches them.
+func (t *folderScopeTracker) walkable(folder client.FolderContent, depth int) bool {
+	if t == nil {
+		return true
+	}
+
+	if t.scope.isExcluded(folder.ID(), folder.Name) {
+		return false
+	}
+
+	return t.scope.MaxDepth == 0 || depth <= t.scope.MaxDepth
+}
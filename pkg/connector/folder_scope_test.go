@@ -0,0 +1,113 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+)
+
+func folderContent(id, name string) client.FolderContent {
+	return client.FolderContent{Id: id, Type: "folder", Name: name}
+}
+
+func TestFolderScopeTracker(t *testing.T) {
+	tracker := newFolderScopeTracker(FolderScope{
+		IncludeNames: []string{"Engineering*"},
+		ExcludeIds:   []string{"archive"},
+		ExcludeNames: []string{"Scratch"},
+		MaxDepth:     3,
+	})
+
+	// Depth 1: only the excluded folders are dropped, the others are needed to reach included trees.
+	kept := tracker.filterFolders(rootId, []client.FolderContent{
+		folderContent("eng", "Engineering"),
+		folderContent("sales", "Sales"),
+		folderContent("archive", "Archive"),
+		{Id: "doc", Type: "document", Name: "Document"},
+	})
+	require.Len(t, kept, 3)
+	require.False(t, tracker.included(rootId))
+	require.True(t, tracker.included("eng"))
+	require.False(t, tracker.included("sales"))
+
+	// Depth 2: folders below an included folder are included.
+	require.True(t, tracker.visit("eng", folderContent("backend", "Backend")))
+	require.False(t, tracker.visit("eng", folderContent("scratch", "Scratch")))
+	require.True(t, tracker.included("backend"))
+
+	// Depth 2: included folders can be anywhere in the tree.
+	require.True(t, tracker.visit("sales", folderContent("sales-eng", "Engineering Sales")))
+	require.True(t, tracker.included("sales-eng"))
+
+	// Depth 3 is the deepest level.
+	require.True(t, tracker.visit("backend", folderContent("services", "Services")))
+	require.False(t, tracker.visit("services", folderContent("deep", "Deep")))
+
+	// Folders of unknown parents, e.g. after resuming a sync, are synced.
+	require.True(t, tracker.visit("unknown", folderContent("orphan", "Orphan")))
+	require.True(t, tracker.included("orphan"))
+}
+
+func TestFolderScopeTrackerResumed(t *testing.T) {
+	tracker := newFolderScopeTracker(FolderScope{MaxDepth: 1})
+
+	// The sync resumes below a folder traversed by a previous process, whose depth is unknown.
+	require.True(t, tracker.visit("resumed", folderContent("a", "A")))
+	require.True(t, tracker.visit("a", folderContent("b", "B")))
+	require.True(t, tracker.included("b"))
+
+	// The folders below it are still checked for cycles.
+	require.False(t, tracker.visit("b", folderContent("a", "A")))
+	require.False(t, tracker.visit("b", folderContent("resumed", "Resumed")))
+
+	// The folder is found under its parent once, which gives it a depth again.
+	require.True(t, tracker.visit(rootId, folderContent("resumed", "Resumed")))
+	require.False(t, tracker.visit("c", folderContent("resumed", "Resumed")))
+	require.False(t, tracker.visit("resumed", folderContent("c", "C")))
+}
+
+func TestFolderScopeTrackerWithoutScope(t *testing.T) {
+	var tracker *folderScopeTracker
+
+	require.True(t, tracker.visit(rootId, folderContent("1", "Folder")))
+	require.True(t, tracker.included("1"))
+
+	tracker = newFolderScopeTracker(FolderScope{})
+	require.True(t, tracker.visit(rootId, folderContent("1", "Folder")))
+	require.True(t, tracker.included(rootId))
+	require.True(t, tracker.included("1"))
+}
+
+func TestFolderScopeTrackerCycles(t *testing.T) {
+	tracker := newFolderScopeTracker(FolderScope{})
+
+	shortcut := folderContent("b", "B")
+	shortcut.Shortcut = true
+
+	kept := tracker.filterFolders(rootId, []client.FolderContent{folderContent("a", "A"), shortcut})
+	require.Len(t, kept, 1)
+
+	// The shortcut didn't claim the folder, which is kept under its real parent.
+	require.True(t, tracker.visit("a", folderContent("b", "B")))
+
+	// Listing the same page again is fine, finding the folder under another parent isn't.
+	require.True(t, tracker.visit("a", folderContent("b", "B")))
+	require.False(t, tracker.visit("b", folderContent("a", "A")))
+	require.True(t, tracker.visit(rootId, folderContent("c", "C")))
+	require.False(t, tracker.visit("c", folderContent("b", "B")))
+}
+
+func TestFolderScopeTrackerReset(t *testing.T) {
+	tracker := newFolderScopeTracker(FolderScope{})
+
+	require.True(t, tracker.visit(rootId, folderContent("a", "A")))
+	require.True(t, tracker.visit("a", folderContent("b", "B")))
+
+	// The next sync finds the folder moved to the root folder.
+	tracker.reset()
+	require.True(t, tracker.visit(rootId, folderContent("b", "B")))
+	require.True(t, tracker.visit(rootId, folderContent("a", "A")))
+	require.False(t, tracker.visit("a", folderContent("b", "B")))
+}
@@ -0,0 +1,120 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+	"github.com/conductorone/baton-lucidchart/pkg/lucidtest"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+func TestFolderBuilderTeamFolderRoles(t *testing.T) {
+	fixtures := testFixtures()
+	fixtures.Folders["300"] = client.Folder{Id: 300, Type: client.FolderTypeTeam, Name: "Engineering"}
+	fixtures.FolderContents["root"] = append(fixtures.FolderContents["root"],
+		client.FolderContent{Id: 300, Type: client.FolderTypeTeam, Name: "Engineering"})
+
+	c, server := newTestConnector(t, fixtures)
+	ctx := context.Background()
+
+	root, err := rs.NewResourceID(folderResourceType, rootId)
+	require.NoError(t, err)
+
+	folders := listAll(t, newFolderBuilder(c.client, c.folderScope, c.trashedPolicy, false, true), root)
+	require.Len(t, folders, 2)
+
+	slugs := func(folder *v2.Resource) []string {
+		// Another builder, as after a restart, tells the team folder apart from its resource only.
+		entitlements, _, _, err := newFolderBuilder(c.client, nil, c.trashedPolicy, false, true).Entitlements(ctx, folder, &pagination.Token{})
+		require.NoError(t, err)
+
+		var rv []string
+		for _, entitlement := range entitlements {
+			rv = append(rv, entitlement.Slug)
+		}
+
+		return rv
+	}
+
+	require.Contains(t, slugs(folders[0]), "user/owner")
+	require.NotContains(t, slugs(folders[1]), "user/owner")
+	require.Zero(t, server.Count(http.MethodGet, "/folders/*"))
+
+	// The type of a folder without description, as the ones of the audit logs, is fetched.
+	teamFolder, err := folderResource("300", "Engineering", "", nil)
+	require.NoError(t, err)
+	require.NotContains(t, slugs(teamFolder), "user/owner")
+	require.Equal(t, 1, server.Count(http.MethodGet, "/folders/300"))
+}
+
+func TestFolderBuilderCreate(t *testing.T) {
+	c, server := newTestConnector(t, testFixtures())
+	ctx := context.Background()
+	folders := newFolderBuilder(c.client, c.folderScope, c.trashedPolicy, false, true)
+
+	folderID := func(id string) *v2.ResourceId {
+		return &v2.ResourceId{ResourceType: folderResourceType.Id, Resource: id}
+	}
+
+	created, _, err := folders.Create(ctx, createRequest(t, nil, "Onboarding", folderID("100"), map[string]interface{}{
+		"collaborators": map[string]interface{}{"2": "edit"},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, "Onboarding", created.DisplayName)
+	require.Equal(t, "100", created.ParentResourceId.Resource)
+
+	id := created.Id.Resource
+	createdID := folderID(id)
+
+	renamed, _, err := folders.Create(ctx, createRequest(t, createdID, "Offboarding", nil, nil))
+	require.NoError(t, err)
+	require.Equal(t, "Offboarding", renamed.DisplayName)
+	require.Equal(t, "100", renamed.ParentResourceId.Resource)
+
+	moved, _, err := folders.Create(ctx, createRequest(t, createdID, "", folderID(rootId), nil))
+	require.NoError(t, err)
+	require.Equal(t, "Offboarding", moved.DisplayName)
+	require.Equal(t, rootId, moved.ParentResourceId.Resource)
+
+	// The folder is read again before each change, so renaming it back isn't taken for a no-op.
+	restored, _, err := folders.Create(ctx, createRequest(t, createdID, "Onboarding", nil, nil))
+	require.NoError(t, err)
+	require.Equal(t, "Onboarding", restored.DisplayName)
+
+	server.Inspect(func(fixtures *lucidtest.Fixtures) {
+		require.Equal(t, "Onboarding", fixtures.Folders[id].Name)
+		require.Zero(t, fixtures.Folders[id].Parent)
+		require.Len(t, fixtures.FolderCollaborators[id], 1)
+		require.Equal(t, "edit", fixtures.FolderCollaborators[id][0].Role)
+	})
+
+	// A new folder whose collaborators can't be added is trashed.
+	server.Fail(lucidtest.Failure{Method: http.MethodPut, Path: "/folders/*/shares/users/*", Status: http.StatusBadRequest})
+
+	_, _, err = folders.Create(ctx, createRequest(t, nil, "Broken", nil, map[string]interface{}{
+		"collaborators": map[string]interface{}{"2": "edit"},
+	}))
+	require.Error(t, err)
+	require.Equal(t, 1, server.Count(http.MethodPost, "/folders/*/trash"))
+
+	server.Inspect(func(fixtures *lucidtest.Fixtures) {
+		for _, folder := range fixtures.Folders {
+			if folder.Name == "Broken" {
+				require.NotNil(t, folder.Trashed)
+			}
+		}
+	})
+
+	// An existing folder is left as is.
+	_, _, err = folders.Create(ctx, createRequest(t, createdID, "", nil, map[string]interface{}{
+		"collaborators": map[string]interface{}{"1": "view"},
+	}))
+	require.Error(t, err)
+	require.Equal(t, 1, server.Count(http.MethodPost, "/folders/*/trash"))
+}
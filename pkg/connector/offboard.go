@@ -0,0 +1,251 @@
+package connector
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+)
+
+const defaultOffboardConcurrency = 4
+
+// OffboardOptions configures an offboarding sweep.
+type OffboardOptions struct {
+	// DryRun only lists the shares that would be removed.
+	DryRun bool
+	// Concurrency is the number of shares removed at the same time, 4 by default. The requests of the sweep
+	// share the rate limit of the client, see WithRequestsPerSecond.
+	Concurrency int
+}
+
+// OffboardItem is a folder or document share of the offboarded user, and the outcome of its removal.
+type OffboardItem struct {
+	ResourceType string `json:"resource_type"`
+	ResourceId   string `json:"resource_id"`
+	Name         string `json:"name"`
+	Role         string `json:"role"`
+	Removed      bool   `json:"removed"`
+	Error        string `json:"error,omitempty"`
+}
+
+// OffboardReport lists every share found for the offboarded user.
+type OffboardReport struct {
+	UserId string         `json:"user_id"`
+	DryRun bool           `json:"dry_run"`
+	Items  []OffboardItem `json:"items"`
+}
+
+// Failed returns the number of shares that couldn't be removed.
+func (r *OffboardReport) Failed() int {
+	failed := 0
+	for _, item := range r.Items {
+		if item.Error != "" {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+// Offboard removes a user from every folder and document shared with them. The shares are found with a
+// live walk of the folder tree, which ignores the folder scope and the folder content cached by the sync so
+// nothing is left behind.
+// Documents owned by the user are reported as failures, their ownership must be transferred instead.
+func (d *Connector) Offboard(ctx context.Context, userId string, opts OffboardOptions) (*OffboardReport, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultOffboardConcurrency
+	}
+
+	items, err := d.findShares(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OffboardReport{
+		UserId: userId,
+		DryRun: opts.DryRun,
+		Items:  items,
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	removeShares(ctx, report.Items, opts.Concurrency, func(ctx context.Context, item OffboardItem) error {
+		if item.ResourceType == folderResourceType.Id {
+			return d.client.DeleteFolderUserCollaborator(ctx, item.ResourceId, userId)
+		}
+
+		return d.client.DeleteDocumentUserCollaborator(ctx, item.ResourceId, userId)
+	})
+
+	return report, nil
+}
+
+// findShares walks the folder tree from the root folder and returns the shares of the user.
+func (d *Connector) findShares(ctx context.Context, userId string) ([]OffboardItem, error) {
+	l := ctxzap.Extract(ctx)
+
+	var items []OffboardItem
+
+	visited := map[string]bool{rootId: true}
+	queue := []string{rootId}
+
+	for len(queue) > 0 {
+		folderId := queue[0]
+		queue = queue[1:]
+
+		err := client.EachPage(
+			ctx,
+			func(ctx context.Context, pageToken string) ([]client.FolderContent, string, error) {
+				return d.client.ListFolderContent(ctx, folderId, pageToken)
+			},
+			func(content []client.FolderContent) (bool, error) {
+				for _, c := range content {
+					// Shortcuts point to content found under its real parent.
+					if c.Shortcut {
+						continue
+					}
+
+					var (
+						item *OffboardItem
+						err  error
+					)
+					switch {
+					case c.IsFolder():
+						if visited[c.ID()] {
+							continue
+						}
+						visited[c.ID()] = true
+						queue = append(queue, c.ID())
+
+						item, err = d.folderShare(ctx, c, userId)
+					case c.Type == "document":
+						item, err = d.documentShare(ctx, c, userId)
+					}
+					if err != nil {
+						return false, err
+					}
+
+					if item != nil {
+						items = append(items, *item)
+					}
+				}
+
+				return true, nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l.Debug("baton-lucidchart: found the shares of the offboarded user", zap.String("user_id", userId), zap.Int("shares", len(items)))
+
+	return items, nil
+}
+
+func (d *Connector) folderShare(ctx context.Context, folder client.FolderContent, userId string) (*OffboardItem, error) {
+	var item *OffboardItem
+
+	err := client.EachPage(
+		ctx,
+		func(ctx context.Context, pageToken string) ([]client.FolderUserCollaboration, string, error) {
+			return d.client.ListFolderUserCollaborators(ctx, folder.ID(), pageToken)
+		},
+		func(collaborators []client.FolderUserCollaboration) (bool, error) {
+			for _, collaborator := range collaborators {
+				if strconv.Itoa(collaborator.UserId) == userId {
+					item = &OffboardItem{
+						ResourceType: folderResourceType.Id,
+						ResourceId:   folder.ID(),
+						Name:         folder.Name,
+						Role:         collaborator.Role,
+					}
+
+					return false, nil
+				}
+			}
+
+			return true, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (d *Connector) documentShare(ctx context.Context, document client.FolderContent, userId string) (*OffboardItem, error) {
+	var item *OffboardItem
+
+	err := client.EachPage(
+		ctx,
+		func(ctx context.Context, pageToken string) ([]client.DocumentUserCollaboration, string, error) {
+			return d.client.ListDocumentUserCollaborators(ctx, document.ID(), pageToken)
+		},
+		func(collaborators []client.DocumentUserCollaboration) (bool, error) {
+			for _, collaborator := range collaborators {
+				if strconv.Itoa(collaborator.UserId) != userId {
+					continue
+				}
+
+				item = &OffboardItem{
+					ResourceType: documentResourceTypeForProduct(document.Product).Id,
+					ResourceId:   document.ID(),
+					Name:         document.Name,
+					Role:         collaborator.Role,
+				}
+
+				if collaborator.Role == documentOwnerRole {
+					item.Error = "the user owns the document, its ownership must be transferred instead"
+				}
+
+				return false, nil
+			}
+
+			return true, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// removeShares removes the shares concurrently and records the outcome of each removal in its item. Items
+// that already have an error are skipped.
+func removeShares(ctx context.Context, items []OffboardItem, concurrency int, remove func(ctx context.Context, item OffboardItem) error) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+
+	for i := range items {
+		if items[i].Error != "" {
+			continue
+		}
+
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func(item *OffboardItem) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			err := remove(ctx, *item)
+			if err != nil {
+				item.Error = err.Error()
+				return
+			}
+
+			item.Removed = true
+		}(&items[i])
+	}
+
+	wg.Wait()
+}
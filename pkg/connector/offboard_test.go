@@ -0,0 +1,114 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+	"github.com/conductorone/baton-lucidchart/pkg/lucidtest"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+)
+
+func TestRemoveShares(t *testing.T) {
+	items := []OffboardItem{
+		{ResourceType: "folder", ResourceId: "1"},
+		{ResourceType: "document", ResourceId: "failing"},
+		{ResourceType: "document", ResourceId: "owned", Role: "owner", Error: "the user owns the document"},
+		{ResourceType: "document", ResourceId: "2"},
+	}
+
+	var calls, running, maxRunning atomic.Int32
+
+	removeShares(context.Background(), items, 2, func(_ context.Context, item OffboardItem) error {
+		calls.Add(1)
+
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			highest := maxRunning.Load()
+			if current <= highest || maxRunning.CompareAndSwap(highest, current) {
+				break
+			}
+		}
+
+		if item.ResourceId == "failing" {
+			return errors.New("boom")
+		}
+
+		return nil
+	})
+
+	// Items that already failed aren't removed.
+	require.Equal(t, int32(3), calls.Load())
+	require.LessOrEqual(t, maxRunning.Load(), int32(2))
+
+	require.True(t, items[0].Removed)
+	require.False(t, items[1].Removed)
+	require.Equal(t, "boom", items[1].Error)
+	require.False(t, items[2].Removed)
+	require.True(t, items[3].Removed)
+
+	report := &OffboardReport{Items: items}
+	require.Equal(t, 2, report.Failed())
+}
+
+func TestOffboardAgainstFakeServer(t *testing.T) {
+	ctx := context.Background()
+	c, server := newTestConnector(t, testFixtures())
+
+	// The folder content listed by a sync doesn't hide the content shared since.
+	_, _, err := c.client.FolderContent(ctx, "100", "")
+	require.NoError(t, err)
+
+	server.Inspect(func(fixtures *lucidtest.Fixtures) {
+		fixtures.FolderContents["100"] = append([]client.FolderContent{
+			{Id: "plan", Type: "document", Name: "Plan", Product: client.ProductLucidchart},
+		}, fixtures.FolderContents["100"]...)
+		fixtures.DocumentCollaborators["plan"] = []client.DocumentUserCollaboration{
+			{DocumentId: "plan", UserId: 2, Role: "view"},
+		}
+	})
+
+	report, err := c.Offboard(ctx, "2", OffboardOptions{DryRun: true})
+	require.NoError(t, err)
+	require.True(t, report.DryRun)
+	require.Equal(t, []OffboardItem{
+		{ResourceType: folderResourceType.Id, ResourceId: "100", Name: "Projects", Role: "view"},
+		{ResourceType: documentResourceType.Id, ResourceId: "plan", Name: "Plan", Role: "view"},
+		{ResourceType: documentResourceType.Id, ResourceId: "doc", Name: "Architecture", Role: "edit"},
+	}, report.Items)
+	require.Zero(t, server.Count(http.MethodDelete, "/*/*/shares/users/*"))
+
+	report, err = c.Offboard(ctx, "2", OffboardOptions{})
+	require.NoError(t, err)
+	require.Len(t, report.Items, 3)
+	require.Zero(t, report.Failed())
+	for _, item := range report.Items {
+		require.True(t, item.Removed)
+	}
+
+	server.Inspect(func(fixtures *lucidtest.Fixtures) {
+		for _, collaborator := range fixtures.FolderCollaborators["100"] {
+			require.NotEqual(t, 2, collaborator.UserId)
+		}
+		for _, collaborator := range fixtures.DocumentCollaborators["doc"] {
+			require.NotEqual(t, 2, collaborator.UserId)
+		}
+		require.Empty(t, fixtures.DocumentCollaborators["plan"])
+	})
+
+	// The owner of a document can't be offboarded until its ownership is transferred.
+	report, err = c.Offboard(ctx, "1", OffboardOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Failed())
+
+	// Users are offboarded with Offboard only, the connector doesn't manage them.
+	var users interface{} = newUserBuilder(c.client)
+	_, ok := users.(connectorbuilder.ResourceManager)
+	require.False(t, ok)
+}
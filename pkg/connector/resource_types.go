@@ -1,6 +1,7 @@
 package connector
 
 import (
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 )
 
@@ -11,12 +12,67 @@ var userResourceType = &v2.ResourceType{
 	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER},
 }
 
+var teamResourceType = &v2.ResourceType{
+	Id:          "team",
+	DisplayName: "Team",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
 var folderResourceType = &v2.ResourceType{
 	Id:          "folder",
 	DisplayName: "Folder",
 }
 
+// documentResourceType is the type of Lucidchart documents, and of the documents of unknown products.
 var documentResourceType = &v2.ResourceType{
 	Id:          "document",
 	DisplayName: "Document",
 }
+
+var lucidsparkBoardResourceType = &v2.ResourceType{
+	Id:          "lucidspark_board",
+	DisplayName: "Lucidspark Board",
+}
+
+var lucidscaleModelResourceType = &v2.ResourceType{
+	Id:          "lucidscale_model",
+	DisplayName: "Lucidscale Model",
+}
+
+// documentResourceTypes are the resource types of the documents of each Lucid product.
+var documentResourceTypes = map[string]*v2.ResourceType{
+	client.ProductLucidchart: documentResourceType,
+	client.ProductLucidspark: lucidsparkBoardResourceType,
+	client.ProductLucidscale: lucidscaleModelResourceType,
+}
+
+// productDocumentResourceTypes lists the document resource types in a stable order.
+var productDocumentResourceTypes = []*v2.ResourceType{
+	documentResourceType,
+	lucidsparkBoardResourceType,
+	lucidscaleModelResourceType,
+}
+
+func documentResourceTypeForProduct(product string) *v2.ResourceType {
+	if resourceType, ok := documentResourceTypes[product]; ok {
+		return resourceType
+	}
+
+	return documentResourceType
+}
+
+func isDocumentResourceType(resourceTypeId string) bool {
+	for _, resourceType := range productDocumentResourceTypes {
+		if resourceType.Id == resourceTypeId {
+			return true
+		}
+	}
+
+	return false
+}
+
+var shareLinkResourceType = &v2.ResourceType{
+	Id:          "share_link",
+	DisplayName: "Share Link",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_SECRET},
+}
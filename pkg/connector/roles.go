@@ -0,0 +1,90 @@
+package connector
+
+import (
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+)
+
+// role is a role collaborators can have on a folder or a document.
+type role struct {
+	Name        string
+	DisplayName string
+	// Description completes "<principal> ... <resource>", e.g. "User can edit and share Roadmap".
+	Description string
+}
+
+// roleCatalog lists the roles of a resource type, ordered from the most to the least privileged. Each
+// role grants everything the roles after it grant.
+type roleCatalog []role
+
+var (
+	ownerRole = role{
+		Name:        "owner",
+		DisplayName: "Owner",
+		Description: "owns",
+	}
+	editAndShareRole = role{
+		Name:        "editandshare",
+		DisplayName: "Editor and sharer",
+		Description: "can edit and share",
+	}
+	editRole = role{
+		Name:        "edit",
+		DisplayName: "Editor",
+		Description: "can edit",
+	}
+	commentRole = role{
+		Name:        "comment",
+		DisplayName: "Commenter",
+		Description: "can comment on",
+	}
+	viewRole = role{
+		Name:        "view",
+		DisplayName: "Viewer",
+		Description: "can view",
+	}
+)
+
+var folderRoles = roleCatalog{ownerRole, editAndShareRole, editRole, commentRole, viewRole}
+
+// teamFolderRoles are the roles on team folders, which are owned by their team rather than by a user.
+var teamFolderRoles = roleCatalog{editAndShareRole, editRole, commentRole, viewRole}
+
+// documentRoles are the roles on the documents of each product. Lucidscale models can't be commented on.
+var documentRoles = map[string]roleCatalog{
+	client.ProductLucidchart: {ownerRole, editAndShareRole, editRole, commentRole, viewRole},
+	client.ProductLucidspark: {ownerRole, editAndShareRole, editRole, commentRole, viewRole},
+	client.ProductLucidscale: {ownerRole, editAndShareRole, editRole, viewRole},
+}
+
+// documentRolesForProduct returns the roles on the documents of a product, the documents of unknown
+// products having the roles of Lucidchart documents, like their resource type.
+func documentRolesForProduct(product string) roleCatalog {
+	if roles, ok := documentRoles[product]; ok {
+		return roles
+	}
+
+	return documentRoles[client.ProductLucidchart]
+}
+
+// rank returns the privilege of a role, higher being more privileged, or -1 when the role isn't in the catalog.
+func (c roleCatalog) rank(name string) int {
+	for i, r := range c {
+		if r.Name == name {
+			return len(c) - i
+		}
+	}
+
+	return -1
+}
+
+func (c roleCatalog) has(name string) bool {
+	return c.rank(name) != -1
+}
+
+// covers returns whether a role grants at least as much as another one. Unknown roles cover nothing and
+// are covered by nothing.
+func (c roleCatalog) covers(name, other string) bool {
+	rank, otherRank := c.rank(name), c.rank(other)
+
+	return rank != -1 && otherRank != -1 && rank >= otherRank
+}
@@ -0,0 +1,39 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+)
+
+func TestRoleCatalogCovers(t *testing.T) {
+	roles := documentRolesForProduct(client.ProductLucidchart)
+
+	tests := []struct {
+		name  string
+		role  string
+		other string
+		want  bool
+	}{
+		{name: "same role", role: "edit", other: "edit", want: true},
+		{name: "more privileged", role: "editandshare", other: "view", want: true},
+		{name: "less privileged", role: "comment", other: "edit", want: false},
+		{name: "unknown role", role: "admin", other: "view", want: false},
+		{name: "unknown other role", role: "owner", other: "admin", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, roles.covers(tt.role, tt.other))
+		})
+	}
+}
+
+func TestDocumentRolesForProduct(t *testing.T) {
+	require.True(t, documentRolesForProduct(client.ProductLucidspark).has("comment"))
+	require.False(t, documentRolesForProduct(client.ProductLucidscale).has("comment"))
+	require.True(t, documentRolesForProduct("unknown").has("comment"))
+	require.False(t, teamFolderRoles.has("owner"))
+}
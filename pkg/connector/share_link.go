@@ -0,0 +1,453 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/crypto"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+
+	"go.uber.org/zap"
+)
+
+const (
+	shareLinkIdSeparator = "/"
+
+	defaultShareLinkRole = "view"
+)
+
+type shareLinkBuilder struct {
+	client *client.LucidchartClient
+	policy *ShareLinkPolicy
+}
+
+func (o *shareLinkBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return shareLinkResourceType
+}
+
+// List returns the share links of a document. Share links are only listed as children of documents.
+func (o *shareLinkBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if parentResourceID == nil {
+		l.Debug("baton-lucidchart: ignoring List call for share links without a parent document")
+		return nil, "", nil, nil
+	}
+
+	if !isDocumentResourceType(parentResourceID.ResourceType) {
+		return nil, "", nil, nil
+	}
+
+	shareLinks, nextToken, err := o.client.ListDocumentShareLinks(ctx, parentResourceID.Resource, pToken.Token)
+	if err != nil {
+		l.Error("Error getting share links", zap.Error(err), zap.String("document_id", parentResourceID.Resource))
+		return nil, "", nil, err
+	}
+
+	var resources []*v2.Resource
+	for _, shareLink := range shareLinks {
+		newResource, err := shareLinkResource(shareLink, parentResourceID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		resources = append(resources, newResource)
+	}
+
+	return resources, nextToken, nil, nil
+}
+
+// Entitlements always returns an empty slice for share links.
+func (o *shareLinkBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for share links since they don't have any entitlements.
+func (o *shareLinkBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Create creates a share link on the parent document of the resource. With the update setting, it updates
+// the role and security settings of the existing share link of the resource id instead, a resource id being
+// rejected otherwise so that a create is never mistaken for an update. The requested settings are read from
+// a structpb.Struct annotation, see shareLinkSettings, and must comply with the sharing policy.
+func (o *shareLinkBuilder) Create(ctx context.Context, resource *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	settings, err := parseShareLinkSettings(resource.Annotations)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hasId := resource.Id != nil && resource.Id.Resource != ""
+
+	switch {
+	case settings.Update && !hasId:
+		return nil, nil, status.Error(codes.InvalidArgument, "baton-lucidchart: updating a share link needs its id")
+	case settings.Update:
+		return o.update(ctx, resource.Id, resource.ParentResourceId, settings)
+	case hasId:
+		return nil, nil, status.Errorf(codes.InvalidArgument, "baton-lucidchart: share link %s already exists, set update to update it", resource.Id.Resource)
+	}
+
+	if resource.ParentResourceId == nil || !isDocumentResourceType(resource.ParentResourceId.ResourceType) {
+		return nil, nil, status.Error(codes.InvalidArgument, "baton-lucidchart: share links can only be created on a document")
+	}
+
+	documentId := resource.ParentResourceId.Resource
+
+	role := defaultShareLinkRole
+	if settings.Role != nil {
+		role = *settings.Role
+	}
+
+	security := settings.apply(client.ShareLinkSecurity{})
+
+	err = o.policy.Validate(role, security, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shareLink, err := o.client.CreateDocumentShareLink(ctx, documentId, role, security)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newResource, err := shareLinkResource(*shareLink, resource.ParentResourceId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newResource, nil, nil
+}
+
+// update applies the settings on top of the current ones of the share link, so the policy is checked
+// against the share link as it will be after the update.
+func (o *shareLinkBuilder) update(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	parentResourceID *v2.ResourceId,
+	settings *shareLinkSettings,
+) (*v2.Resource, annotations.Annotations, error) {
+	documentId, shareLinkId, err := parseShareLinkId(resourceId.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current, err := o.client.GetDocumentShareLink(ctx, documentId, shareLinkId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	role := current.Role
+	if settings.Role != nil {
+		role = *settings.Role
+	}
+
+	security := settings.apply(current.LinkSecurity)
+
+	err = o.policy.Validate(role, security, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shareLink, err := o.client.UpdateDocumentShareLink(ctx, documentId, shareLinkId, role, security)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	documentID := parentResourceID
+	if documentID == nil {
+		documentID, err = rs.NewResourceID(documentResourceType, documentId)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	newResource, err := shareLinkResource(*shareLink, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newResource, nil, nil
+}
+
+// Delete revokes the share link, so that nobody can use its accept URL anymore.
+func (o *shareLinkBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	if resourceId.ResourceType != shareLinkResourceType.Id {
+		return nil, fmt.Errorf("resource type %s is not supported", resourceId.ResourceType)
+	}
+
+	documentId, shareLinkId, err := parseShareLinkId(resourceId.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.client.DeleteDocumentShareLink(ctx, documentId, shareLinkId)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// Rotate replaces the passcode of the share link with a new one generated from the credential options.
+// Everyone using the share link needs the new passcode afterwards. Only the passcode rules of the policy are
+// checked, so that the passcode of a share link breaking other rules can still be changed.
+func (o *shareLinkBuilder) Rotate(ctx context.Context, resourceId *v2.ResourceId, credentialOptions *v2.CredentialOptions) ([]*v2.PlaintextData, annotations.Annotations, error) {
+	if resourceId.ResourceType != shareLinkResourceType.Id {
+		return nil, nil, fmt.Errorf("resource type %s is not supported", resourceId.ResourceType)
+	}
+
+	documentId, shareLinkId, err := parseShareLinkId(resourceId.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	passcode, err := crypto.GeneratePassword(credentialOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = o.policy.ValidatePasscode(passcode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current, err := o.client.GetDocumentShareLink(ctx, documentId, shareLinkId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	security := current.LinkSecurity
+	security.Passcode = passcode
+
+	_, err = o.client.UpdateDocumentShareLink(ctx, documentId, shareLinkId, current.Role, security)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plaintext := &v2.PlaintextData{
+		Name:        "passcode",
+		Description: fmt.Sprintf("Passcode of share link %s", current.AcceptUrl),
+		Bytes:       []byte(passcode),
+	}
+
+	return []*v2.PlaintextData{plaintext}, nil, nil
+}
+
+func (o *shareLinkBuilder) RotateCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return &v2.CredentialDetailsCredentialRotation{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+	}, nil, nil
+}
+
+// shareLinkResource returns the resource of a share link of the parent document. Its id is built from the
+// id of the parent rather than from the document of the share link, so that it always matches the parent.
+func shareLinkResource(shareLink client.DocumentShareLink, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	secretOptions := []rs.SecretTraitOption{
+		rs.WithSecretCreatedAt(shareLink.Created),
+	}
+
+	if shareLink.LinkSecurity.Expires != nil {
+		secretOptions = append(secretOptions, rs.WithSecretExpiresAt(*shareLink.LinkSecurity.Expires))
+	}
+
+	if shareLink.CreatedBy != 0 {
+		creatorID, err := rs.NewResourceID(userResourceType, shareLink.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+
+		secretOptions = append(secretOptions, rs.WithSecretCreatedByID(creatorID))
+	}
+
+	return rs.NewSecretResource(
+		fmt.Sprintf("%s share link %s", shareLink.Role, shareLink.ShareLinkId),
+		shareLinkResourceType,
+		newShareLinkId(parentResourceID.Resource, shareLink.ShareLinkId),
+		secretOptions,
+		rs.WithParentResourceID(parentResourceID),
+		rs.WithDescription(shareLinkDescription(shareLink)),
+	)
+}
+
+// shareLinkDescription summarizes the role and security settings of the share link, which are what
+// reviewers need to assess the exposure of a document.
+func shareLinkDescription(shareLink client.DocumentShareLink) string {
+	security := shareLink.LinkSecurity
+
+	expires := "never"
+	if security.Expires != nil {
+		expires = security.Expires.String()
+	}
+
+	return fmt.Sprintf(
+		"role: %s, restrict to account: %t, allow anonymous: %t, passcode: %t, expires: %s, accept url: %s",
+		shareLink.Role,
+		security.RestrictToAccount,
+		security.AllowAnonymous,
+		security.Passcode != "",
+		expires,
+		shareLink.AcceptUrl,
+	)
+}
+
+// shareLinkSettings are the role and security settings requested for a share link. They are sent as a
+// structpb.Struct annotation with the keys role, restrict_to_account, allow_anonymous, passcode, expires
+// (RFC 3339) and expires_in (a duration from now). Unset settings keep their current value. The update key
+// makes Create update an existing share link.
+type shareLinkSettings struct {
+	Update bool
+
+	Role              *string
+	RestrictToAccount *bool
+	AllowAnonymous    *bool
+	Passcode          *string
+	Expires           *time.Time
+}
+
+func (s *shareLinkSettings) apply(security client.ShareLinkSecurity) client.ShareLinkSecurity {
+	if s.RestrictToAccount != nil {
+		security.RestrictToAccount = *s.RestrictToAccount
+	}
+
+	if s.AllowAnonymous != nil {
+		security.AllowAnonymous = *s.AllowAnonymous
+	}
+
+	if s.Passcode != nil {
+		security.Passcode = *s.Passcode
+	}
+
+	if s.Expires != nil {
+		security.Expires = s.Expires
+	}
+
+	return security
+}
+
+func parseShareLinkSettings(annos annotations.Annotations) (*shareLinkSettings, error) {
+	settings := &shareLinkSettings{}
+
+	fields := &structpb.Struct{}
+	ok, err := annos.Pick(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return settings, nil
+	}
+
+	for key, value := range fields.AsMap() {
+		switch key {
+		case "update":
+			update, ok := value.(bool)
+			if !ok {
+				return nil, invalidShareLinkSetting(key, value)
+			}
+			settings.Update = update
+
+		case "role":
+			role, ok := value.(string)
+			if !ok {
+				return nil, invalidShareLinkSetting(key, value)
+			}
+			settings.Role = &role
+
+		case "restrict_to_account":
+			restrict, ok := value.(bool)
+			if !ok {
+				return nil, invalidShareLinkSetting(key, value)
+			}
+			settings.RestrictToAccount = &restrict
+
+		case "allow_anonymous":
+			anonymous, ok := value.(bool)
+			if !ok {
+				return nil, invalidShareLinkSetting(key, value)
+			}
+			settings.AllowAnonymous = &anonymous
+
+		case "passcode":
+			passcode, ok := value.(string)
+			if !ok {
+				return nil, invalidShareLinkSetting(key, value)
+			}
+			settings.Passcode = &passcode
+
+		case "expires":
+			raw, ok := value.(string)
+			if !ok {
+				return nil, invalidShareLinkSetting(key, value)
+			}
+
+			expires, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return nil, invalidShareLinkSetting(key, value)
+			}
+			settings.Expires = &expires
+
+		case "expires_in":
+			raw, ok := value.(string)
+			if !ok {
+				return nil, invalidShareLinkSetting(key, value)
+			}
+
+			duration, err := time.ParseDuration(raw)
+			if err != nil {
+				return nil, invalidShareLinkSetting(key, value)
+			}
+			expires := time.Now().Add(duration)
+			settings.Expires = &expires
+
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "baton-lucidchart: unknown share link setting %s", key)
+		}
+	}
+
+	return settings, nil
+}
+
+func invalidShareLinkSetting(key string, value interface{}) error {
+	return status.Errorf(codes.InvalidArgument, "baton-lucidchart: invalid value %v for share link setting %s", value, key)
+}
+
+// newShareLinkId builds the resource id of a share link. The document id is part of it because every
+// share link operation is scoped to its document.
+func newShareLinkId(documentId, shareLinkId string) string {
+	return documentId + shareLinkIdSeparator + shareLinkId
+}
+
+func parseShareLinkId(id string) (string, string, error) {
+	documentId, shareLinkId, found := strings.Cut(id, shareLinkIdSeparator)
+	if !found || documentId == "" || shareLinkId == "" {
+		return "", "", fmt.Errorf("invalid share link id %s", id)
+	}
+
+	return documentId, shareLinkId, nil
+}
+
+func newShareLinkBuilder(client *client.LucidchartClient, policy *ShareLinkPolicy) *shareLinkBuilder {
+	return &shareLinkBuilder{
+		client: client,
+		policy: policy,
+	}
+}
@@ -0,0 +1,86 @@
+package connector
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+)
+
+// ShareLinkPolicy is the organization policy enforced on every share link created or updated through
+// the connector. The zero value allows everything Lucid allows.
+type ShareLinkPolicy struct {
+	// MaxExpiration is the longest a share link can stay valid. When set, share links must expire.
+	MaxExpiration time.Duration
+
+	// DenyAnonymous rejects share links that can be used without a Lucid account.
+	DenyAnonymous bool
+
+	// RequirePasscode rejects share links without a passcode.
+	RequirePasscode bool
+
+	// RequireAccountRestriction rejects share links that can be used outside of the Lucid account.
+	RequireAccountRestriction bool
+
+	// AllowedRoles are the roles share links can grant. Empty allows every role.
+	AllowedRoles []string
+}
+
+// Validate returns a PermissionDenied error listing every rule of the policy the share link violates.
+func (p *ShareLinkPolicy) Validate(role string, security client.ShareLinkSecurity, now time.Time) error {
+	if p == nil {
+		return nil
+	}
+
+	var violations []string
+
+	if len(p.AllowedRoles) > 0 && !slices.Contains(p.AllowedRoles, role) {
+		violations = append(violations, fmt.Sprintf("role %s is not allowed", role))
+	}
+
+	if p.DenyAnonymous && security.AllowAnonymous {
+		violations = append(violations, "anonymous access is not allowed")
+	}
+
+	if p.RequirePasscode && security.Passcode == "" {
+		violations = append(violations, "a passcode is required")
+	}
+
+	if p.RequireAccountRestriction && !security.RestrictToAccount {
+		violations = append(violations, "the link must be restricted to the account")
+	}
+
+	if p.MaxExpiration > 0 {
+		switch {
+		case security.Expires == nil:
+			violations = append(violations, "an expiration is required")
+		case security.Expires.After(now.Add(p.MaxExpiration)):
+			violations = append(violations, fmt.Sprintf("the expiration can't be later than %s from now", p.MaxExpiration))
+		}
+	}
+
+	if len(violations) > 0 {
+		return status.Errorf(codes.PermissionDenied, "baton-lucidchart: share link violates the sharing policy: %s", strings.Join(violations, ", "))
+	}
+
+	return nil
+}
+
+// ValidatePasscode returns a PermissionDenied error when the passcode violates the passcode rules of the
+// policy, the only ones a new passcode can break.
+func (p *ShareLinkPolicy) ValidatePasscode(passcode string) error {
+	if p == nil {
+		return nil
+	}
+
+	if p.RequirePasscode && passcode == "" {
+		return status.Error(codes.PermissionDenied, "baton-lucidchart: share link violates the sharing policy: a passcode is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package connector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+)
+
+func TestShareLinkPolicyValidate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	inAWeek := now.Add(7 * 24 * time.Hour)
+	inAYear := now.Add(365 * 24 * time.Hour)
+
+	strict := &ShareLinkPolicy{
+		MaxExpiration:             30 * 24 * time.Hour,
+		DenyAnonymous:             true,
+		RequirePasscode:           true,
+		RequireAccountRestriction: true,
+		AllowedRoles:              []string{"view", "comment"},
+	}
+
+	cases := []struct {
+		Name     string
+		Policy   *ShareLinkPolicy
+		Role     string
+		Security client.ShareLinkSecurity
+		Valid    bool
+	}{
+		{
+			Name:     "no policy",
+			Policy:   nil,
+			Role:     "edit",
+			Security: client.ShareLinkSecurity{AllowAnonymous: true},
+			Valid:    true,
+		},
+		{
+			Name:     "empty policy",
+			Policy:   &ShareLinkPolicy{},
+			Role:     "edit",
+			Security: client.ShareLinkSecurity{AllowAnonymous: true},
+			Valid:    true,
+		},
+		{
+			Name:   "compliant link",
+			Policy: strict,
+			Role:   "view",
+			Security: client.ShareLinkSecurity{
+				RestrictToAccount: true,
+				Expires:           &inAWeek,
+				Passcode:          "secret",
+			},
+			Valid: true,
+		},
+		{
+			Name:   "role not allowed",
+			Policy: strict,
+			Role:   "edit",
+			Security: client.ShareLinkSecurity{
+				RestrictToAccount: true,
+				Expires:           &inAWeek,
+				Passcode:          "secret",
+			},
+		},
+		{
+			Name:   "anonymous",
+			Policy: strict,
+			Role:   "view",
+			Security: client.ShareLinkSecurity{
+				RestrictToAccount: true,
+				Expires:           &inAWeek,
+				Passcode:          "secret",
+				AllowAnonymous:    true,
+			},
+		},
+		{
+			Name:   "missing passcode",
+			Policy: strict,
+			Role:   "view",
+			Security: client.ShareLinkSecurity{
+				RestrictToAccount: true,
+				Expires:           &inAWeek,
+			},
+		},
+		{
+			Name:   "not restricted to account",
+			Policy: strict,
+			Role:   "view",
+			Security: client.ShareLinkSecurity{
+				Expires:  &inAWeek,
+				Passcode: "secret",
+			},
+		},
+		{
+			Name:   "never expires",
+			Policy: strict,
+			Role:   "view",
+			Security: client.ShareLinkSecurity{
+				RestrictToAccount: true,
+				Passcode:          "secret",
+			},
+		},
+		{
+			Name:   "expires too late",
+			Policy: strict,
+			Role:   "view",
+			Security: client.ShareLinkSecurity{
+				RestrictToAccount: true,
+				Expires:           &inAYear,
+				Passcode:          "secret",
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.Name, func(t *testing.T) {
+			err := s.Policy.Validate(s.Role, s.Security, now)
+			if s.Valid {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			require.Equal(t, codes.PermissionDenied, status.Code(err))
+		})
+	}
+}
+
+func TestShareLinkPolicyValidatePasscode(t *testing.T) {
+	var policy *ShareLinkPolicy
+	require.NoError(t, policy.ValidatePasscode(""))
+
+	policy = &ShareLinkPolicy{RequirePasscode: true, DenyAnonymous: true}
+	require.NoError(t, policy.ValidatePasscode("secret"))
+	require.Equal(t, codes.PermissionDenied, status.Code(policy.ValidatePasscode("")))
+}
@@ -0,0 +1,141 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+	"github.com/conductorone/baton-lucidchart/pkg/lucidtest"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+func shareLinkFixtures() map[string][]client.DocumentShareLink {
+	return map[string][]client.DocumentShareLink{
+		"doc": {
+			{ShareLinkId: "link", Role: "view", AcceptUrl: "https://lucid.app/share/link"},
+		},
+	}
+}
+
+func TestShareLinkBuilderList(t *testing.T) {
+	fixtures := testFixtures()
+	fixtures.ShareLinks = shareLinkFixtures()
+
+	c, _ := newTestConnector(t, fixtures)
+
+	document, err := rs.NewResourceID(documentResourceType, "doc")
+	require.NoError(t, err)
+
+	// Lucid doesn't always return the document of the share links, their ids come from their parent.
+	shareLinks := listAll(t, newShareLinkBuilder(c.client, &ShareLinkPolicy{}), document)
+	require.Len(t, shareLinks, 1)
+	require.Equal(t, "doc/link", shareLinks[0].Id.Resource)
+	require.Equal(t, document, shareLinks[0].ParentResourceId)
+}
+
+func TestShareLinkBuilderCreateAndUpdate(t *testing.T) {
+	fixtures := testFixtures()
+	fixtures.ShareLinks = shareLinkFixtures()
+
+	c, server := newTestConnector(t, fixtures)
+	ctx := context.Background()
+	shareLinks := newShareLinkBuilder(c.client, &ShareLinkPolicy{})
+
+	document, err := rs.NewResourceID(documentResourceType, "doc")
+	require.NoError(t, err)
+
+	link := &v2.ResourceId{ResourceType: shareLinkResourceType.Id, Resource: "doc/link"}
+
+	created, _, err := shareLinks.Create(ctx, createRequest(t, nil, "", document, map[string]interface{}{"role": "edit"}))
+	require.NoError(t, err)
+	require.NotEqual(t, "doc/link", created.Id.Resource)
+
+	// An id without the update setting is rejected rather than silently updating the share link.
+	_, _, err = shareLinks.Create(ctx, createRequest(t, link, "", document, map[string]interface{}{"role": "edit"}))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, _, err = shareLinks.Create(ctx, createRequest(t, nil, "", document, map[string]interface{}{"update": true, "role": "edit"}))
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	updated, _, err := shareLinks.Create(ctx, createRequest(t, link, "", document, map[string]interface{}{"update": true, "role": "edit"}))
+	require.NoError(t, err)
+	require.Equal(t, "doc/link", updated.Id.Resource)
+
+	server.Inspect(func(fixtures *lucidtest.Fixtures) {
+		require.Len(t, fixtures.ShareLinks["doc"], 2)
+		require.Equal(t, "edit", fixtures.ShareLinks["doc"][0].Role)
+	})
+}
+
+func TestShareLinkBuilderRotate(t *testing.T) {
+	fixtures := testFixtures()
+	fixtures.ShareLinks = shareLinkFixtures()
+
+	c, server := newTestConnector(t, fixtures)
+	ctx := context.Background()
+	shareLinks := newShareLinkBuilder(c.client, &ShareLinkPolicy{})
+
+	id := &v2.ResourceId{ResourceType: shareLinkResourceType.Id, Resource: "doc/link"}
+	options := &v2.CredentialOptions{
+		Options: &v2.CredentialOptions_RandomPassword_{
+			RandomPassword: &v2.CredentialOptions_RandomPassword{Length: 24},
+		},
+	}
+
+	plaintexts, _, err := shareLinks.Rotate(ctx, id, options)
+	require.NoError(t, err)
+	require.Len(t, plaintexts, 1)
+
+	passcode := string(plaintexts[0].Bytes)
+	require.Len(t, passcode, 24)
+
+	// The new passcode is sent in the PATCH body, keeping the role of the share link.
+	var patches []lucidtest.Request
+	for _, r := range server.Requests() {
+		if r.Method == http.MethodPatch {
+			patches = append(patches, r)
+		}
+	}
+	require.Len(t, patches, 1)
+	require.Equal(t, "/documents/doc/shares/shareLinks/link", patches[0].Path)
+
+	var body struct {
+		Role         string                   `json:"role"`
+		LinkSecurity client.ShareLinkSecurity `json:"linkSecurity"`
+	}
+	require.NoError(t, json.Unmarshal(patches[0].Body, &body))
+	require.Equal(t, "view", body.Role)
+	require.Equal(t, passcode, body.LinkSecurity.Passcode)
+
+	// An update right after keeps the new passcode, the share link being read again rather than from a cache.
+	document, err := rs.NewResourceID(documentResourceType, "doc")
+	require.NoError(t, err)
+
+	_, _, err = shareLinks.Create(ctx, createRequest(t, id, "", document, map[string]interface{}{"update": true, "role": "edit"}))
+	require.NoError(t, err)
+
+	server.Inspect(func(fixtures *lucidtest.Fixtures) {
+		require.Equal(t, "edit", fixtures.ShareLinks["doc"][0].Role)
+		require.Equal(t, passcode, fixtures.ShareLinks["doc"][0].LinkSecurity.Passcode)
+	})
+
+	// Passwords shorter than the credential options allow are rejected before any update.
+	options.GetRandomPassword().Length = 4
+	_, _, err = shareLinks.Rotate(ctx, id, options)
+	require.Error(t, err)
+	require.Equal(t, 2, server.Count(http.MethodPatch, "/documents/*/shares/shareLinks/*"))
+
+	// Only the passcode rules are checked, so that a share link breaking the others can still be remediated.
+	options.GetRandomPassword().Length = 24
+	strict := newShareLinkBuilder(c.client, &ShareLinkPolicy{RequirePasscode: true, RequireAccountRestriction: true})
+	_, _, err = strict.Rotate(ctx, id, options)
+	require.NoError(t, err)
+	require.Equal(t, 3, server.Count(http.MethodPatch, "/documents/*/shares/shareLinks/*"))
+}
@@ -0,0 +1,342 @@
+package connector
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+	"github.com/conductorone/baton-lucidchart/pkg/lucidtest"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/dotc1z"
+	sdkSync "github.com/conductorone/baton-sdk/pkg/sync"
+	"github.com/conductorone/baton-sdk/pkg/types"
+)
+
+// connectorClient gathers the clients of every connector service, as the syncer expects.
+type connectorClient struct {
+	v2.ResourceTypesServiceClient
+	v2.ResourcesServiceClient
+	v2.EntitlementsServiceClient
+	v2.GrantsServiceClient
+	v2.ConnectorServiceClient
+	v2.AssetServiceClient
+	v2.GrantManagerServiceClient
+	v2.ResourceManagerServiceClient
+	v2.AccountManagerServiceClient
+	v2.CredentialManagerServiceClient
+	v2.EventServiceClient
+	v2.TicketsServiceClient
+}
+
+// newConnectorClient serves the connector over a loopback gRPC connection and returns a client of it.
+func newConnectorClient(t *testing.T, c *Connector) types.ConnectorClient {
+	t.Helper()
+
+	ctx := context.Background()
+
+	connector, err := connectorbuilder.NewConnector(ctx, c)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	v2.RegisterResourceTypesServiceServer(server, connector)
+	v2.RegisterResourcesServiceServer(server, connector)
+	v2.RegisterEntitlementsServiceServer(server, connector)
+	v2.RegisterGrantsServiceServer(server, connector)
+	v2.RegisterConnectorServiceServer(server, connector)
+	v2.RegisterAssetServiceServer(server, connector)
+	v2.RegisterGrantManagerServiceServer(server, connector)
+	v2.RegisterResourceManagerServiceServer(server, connector)
+	v2.RegisterAccountManagerServiceServer(server, connector)
+	v2.RegisterCredentialManagerServiceServer(server, connector)
+	v2.RegisterEventServiceServer(server, connector)
+	v2.RegisterTicketsServiceServer(server, connector)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(ctx, listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return &connectorClient{
+		ResourceTypesServiceClient:     v2.NewResourceTypesServiceClient(conn),
+		ResourcesServiceClient:         v2.NewResourcesServiceClient(conn),
+		EntitlementsServiceClient:      v2.NewEntitlementsServiceClient(conn),
+		GrantsServiceClient:            v2.NewGrantsServiceClient(conn),
+		ConnectorServiceClient:         v2.NewConnectorServiceClient(conn),
+		AssetServiceClient:             v2.NewAssetServiceClient(conn),
+		GrantManagerServiceClient:      v2.NewGrantManagerServiceClient(conn),
+		ResourceManagerServiceClient:   v2.NewResourceManagerServiceClient(conn),
+		AccountManagerServiceClient:    v2.NewAccountManagerServiceClient(conn),
+		CredentialManagerServiceClient: v2.NewCredentialManagerServiceClient(conn),
+		EventServiceClient:             v2.NewEventServiceClient(conn),
+		TicketsServiceClient:           v2.NewTicketsServiceClient(conn),
+	}
+}
+
+// syncFixtures are an account with nested folders, whose contents and collaborators span several pages.
+func syncFixtures() lucidtest.Fixtures {
+	fixtures := testFixtures()
+
+	fixtures.Folders["200"] = client.Folder{Id: 200, Type: client.FolderTypeFolder, Name: "Designs", Parent: 100}
+	fixtures.FolderContents["100"] = append(fixtures.FolderContents["100"], client.FolderContent{Id: 200, Type: client.FolderTypeFolder, Name: "Designs"})
+	fixtures.FolderContents["200"] = []client.FolderContent{
+		{Id: "wireframes", Type: "document", Name: "Wireframes", Product: client.ProductLucidchart},
+	}
+	fixtures.FolderCollaborators["200"] = []client.FolderUserCollaboration{
+		{FolderId: 200, UserId: 1, Role: "owner"},
+		{FolderId: 200, UserId: 2, Role: "edit"},
+		{FolderId: 200, UserId: 3, Role: "comment"},
+	}
+
+	fixtures.Users = append(fixtures.Users, client.User{UserId: 3, Email: "joan@example.com", Name: "Joan"})
+
+	fixtures.Documents["wireframes"] = client.Document{
+		DocumentId: "wireframes",
+		Title:      "Wireframes",
+		Product:    client.ProductLucidchart,
+		Parent:     200,
+		Owner:      client.DocumentOwner{Id: 2, Type: client.OwnerTypeUser, Name: "John"},
+	}
+	fixtures.DocumentCollaborators["wireframes"] = []client.DocumentUserCollaboration{
+		{DocumentId: "wireframes", UserId: 2, Role: "owner"},
+		{DocumentId: "wireframes", UserId: 3, Role: "view"},
+	}
+
+	return fixtures
+}
+
+// syncC1Z runs a full sync of the connector into a c1z file, and opens the file to read the sync.
+func syncC1Z(t *testing.T, c *Connector) *dotc1z.C1File {
+	t.Helper()
+
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	c1zPath := filepath.Join(dir, "sync.c1z")
+
+	syncer, err := sdkSync.NewSyncer(ctx, newConnectorClient(t, c), sdkSync.WithC1ZPath(c1zPath), sdkSync.WithTmpDir(dir))
+	require.NoError(t, err)
+
+	err = syncer.Sync(ctx)
+	require.NoError(t, err)
+
+	err = syncer.Close(ctx)
+	require.NoError(t, err)
+
+	file, err := dotc1z.NewC1ZFile(ctx, c1zPath, dotc1z.WithTmpDir(dir))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = file.Close()
+	})
+
+	syncId, err := file.LatestSyncID(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, syncId)
+
+	err = file.ViewSync(ctx, syncId)
+	require.NoError(t, err)
+
+	return file
+}
+
+// syncedResources returns the resources of the sync, by resource type and id.
+func syncedResources(t *testing.T, file *dotc1z.C1File) map[string]*v2.Resource {
+	t.Helper()
+
+	resources := map[string]*v2.Resource{}
+	resourcesResp, err := file.ListResources(context.Background(), &v2.ResourcesServiceListResourcesRequest{})
+	require.NoError(t, err)
+	require.Empty(t, resourcesResp.NextPageToken)
+	for _, resource := range resourcesResp.List {
+		resources[resource.Id.ResourceType+":"+resource.Id.Resource] = resource
+	}
+
+	return resources
+}
+
+// syncedGrants returns the grants of the sync, as entitlement=principal keys.
+func syncedGrants(t *testing.T, file *dotc1z.C1File) map[string]bool {
+	t.Helper()
+
+	grants := map[string]bool{}
+	grantsResp, err := file.ListGrants(context.Background(), &v2.GrantsServiceListGrantsRequest{})
+	require.NoError(t, err)
+	require.Empty(t, grantsResp.NextPageToken)
+	for _, grant := range grantsResp.List {
+		grants[grant.Entitlement.Id+"="+grant.Principal.Id.ResourceType+":"+grant.Principal.Id.Resource] = true
+	}
+
+	return grants
+}
+
+func TestSyncWritesC1Z(t *testing.T) {
+	c, _ := newTestConnector(t, syncFixtures())
+
+	file := syncC1Z(t, c)
+	resources := syncedResources(t, file)
+
+	require.Contains(t, resources, "user:3")
+	require.Contains(t, resources, "folder:root")
+	require.Equal(t, "folder:root", parentKey(resources["folder:100"]))
+	require.Equal(t, "folder:100", parentKey(resources["folder:200"]))
+	require.Equal(t, "folder:100", parentKey(resources["document:doc"]))
+	require.Equal(t, "folder:200", parentKey(resources["document:wireframes"]))
+	require.Equal(t, "folder:100", parentKey(resources["lucidspark_board:board"]))
+
+	entitlements := map[string]bool{}
+	entitlementsResp, err := file.ListEntitlements(context.Background(), &v2.EntitlementsServiceListEntitlementsRequest{})
+	require.NoError(t, err)
+	require.Empty(t, entitlementsResp.NextPageToken)
+	for _, entitlement := range entitlementsResp.List {
+		entitlements[entitlement.Id] = true
+	}
+
+	require.True(t, entitlements["folder:200:user/comment"])
+	require.True(t, entitlements["document:wireframes:user/view"])
+
+	grants := syncedGrants(t, file)
+
+	// Every page of the collaborators is synced, and the unknown role is skipped.
+	require.True(t, grants["folder:100:user/owner=user:1"])
+	require.True(t, grants["folder:100:user/view=user:2"])
+	require.True(t, grants["folder:200:user/owner=user:1"])
+	require.True(t, grants["folder:200:user/edit=user:2"])
+	require.True(t, grants["folder:200:user/comment=user:3"])
+	require.True(t, grants["document:doc:user/owner=user:1"])
+	require.True(t, grants["document:doc:user/edit=user:2"])
+	require.False(t, grants["document:doc:user/admin=user:3"])
+	require.True(t, grants["document:wireframes:user/owner=user:2"])
+	require.True(t, grants["document:wireframes:user/view=user:3"])
+}
+
+func TestSyncReplaysCassette(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.jsonl")
+
+	recorder, server := newTestConnector(t, syncFixtures(), WithCassette(client.CassetteRecord, cassette))
+	recorded := syncC1Z(t, recorder)
+
+	// The replay doesn't need the server.
+	server.Close()
+
+	player, err := New(
+		context.Background(),
+		"api-key",
+		"",
+		"client-id",
+		"client-secret",
+		"https://example.com",
+		"refresh-token",
+		WithBaseUrl(server.URL),
+		WithCassette(client.CassetteReplay, cassette),
+	)
+	require.NoError(t, err)
+	replayed := syncC1Z(t, player)
+
+	recordedResources := syncedResources(t, recorded)
+	replayedResources := syncedResources(t, replayed)
+	require.Len(t, replayedResources, len(recordedResources))
+	for key, resource := range recordedResources {
+		require.Contains(t, replayedResources, key)
+		require.Equal(t, parentKey(resource), parentKey(replayedResources[key]))
+	}
+
+	require.Equal(t, syncedGrants(t, recorded), syncedGrants(t, replayed))
+}
+
+func TestSyncWithPrefetch(t *testing.T) {
+	c, _ := newTestConnector(t, syncFixtures())
+	want := syncC1Z(t, c)
+
+	prefetched, _ := newTestConnector(t, syncFixtures(), WithPrefetch(4))
+	got := syncC1Z(t, prefetched)
+
+	wantResources := syncedResources(t, want)
+	gotResources := syncedResources(t, got)
+	require.Len(t, gotResources, len(wantResources))
+	for key, resource := range wantResources {
+		require.Contains(t, gotResources, key)
+		require.Equal(t, parentKey(resource), parentKey(gotResources[key]))
+	}
+
+	require.Equal(t, syncedGrants(t, want), syncedGrants(t, got))
+}
+
+func TestSyncWithPrefetchExcludingTrash(t *testing.T) {
+	fixtures := syncFixtures()
+	trashed := time.Now()
+	designs := fixtures.Folders["200"]
+	designs.Trashed = &trashed
+	fixtures.Folders["200"] = designs
+	fixtures.Documents["board"] = client.Document{DocumentId: "board", Title: "Retro", Product: client.ProductLucidspark, Parent: 100}
+
+	c, server := newTestConnector(t, fixtures, WithPrefetch(4), WithTrashedContentPolicy(TrashedContentExclude))
+	resources := syncedResources(t, syncC1Z(t, c))
+
+	require.NotContains(t, resources, folderResourceType.Id+":200")
+	require.Contains(t, resources, folderResourceType.Id+":100")
+
+	// Neither the prefetch nor the syncer list the trashed folder.
+	require.Zero(t, server.Count(http.MethodGet, "/folders/200/contents"))
+}
+
+func TestSyncWithCollaboratorLoader(t *testing.T) {
+	c, _ := newTestConnector(t, syncFixtures())
+	want := syncC1Z(t, c)
+
+	loaded, server := newTestConnector(t, syncFixtures(), WithCollaboratorLoader(4))
+	got := syncC1Z(t, loaded)
+
+	require.Equal(t, syncedGrants(t, want), syncedGrants(t, got))
+
+	// Each page of collaborators is requested once, by either the loader or the syncer.
+	require.Equal(t, 3, server.Count(http.MethodGet, "/documents/doc/shares/users"))
+	require.Equal(t, 2, server.Count(http.MethodGet, "/documents/wireframes/shares/users"))
+}
+
+func TestSyncAfterMove(t *testing.T) {
+	// Both syncs run in the same process, with the default settings of the SDK.
+	c, server := newTestConnector(t, syncFixtures())
+
+	resources := syncedResources(t, syncC1Z(t, c))
+	require.Equal(t, "folder:100", parentKey(resources["folder:200"]))
+
+	// The folder is moved to the root folder between the syncs.
+	server.Inspect(func(fixtures *lucidtest.Fixtures) {
+		moved := fixtures.FolderContents["100"][2]
+		fixtures.FolderContents["100"] = fixtures.FolderContents["100"][:2]
+		fixtures.FolderContents["root"] = append(fixtures.FolderContents["root"], moved)
+
+		folder := fixtures.Folders["200"]
+		folder.Parent = 0
+		fixtures.Folders["200"] = folder
+	})
+
+	resources = syncedResources(t, syncC1Z(t, c))
+	require.Equal(t, "folder:root", parentKey(resources["folder:200"]))
+	require.Equal(t, "folder:200", parentKey(resources["document:wireframes"]))
+}
+
+func parentKey(resource *v2.Resource) string {
+	if resource == nil || resource.ParentResourceId == nil {
+		return ""
+	}
+
+	return resource.ParentResourceId.ResourceType + ":" + resource.ParentResourceId.Resource
+}
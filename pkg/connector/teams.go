@@ -0,0 +1,79 @@
+package connector
+
+import (
+	"context"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+type teamBuilder struct {
+	client *client.LucidchartClient
+}
+
+func (o *teamBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return teamResourceType
+}
+
+// List returns all the teams of the account. Teams are synced so that team-owned documents
+// can be granted to them.
+func (o *teamBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	teams, nextToken, err := o.client.ListTeams(ctx, pToken.Token)
+	if err != nil {
+		l.Error("Error getting teams", zap.Error(err))
+		return nil, "", nil, err
+	}
+
+	var resources []*v2.Resource
+	for _, t := range teams {
+		team, err := teamResource(t)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		resources = append(resources, team)
+	}
+
+	return resources, nextToken, nil, nil
+}
+
+// Entitlements always returns an empty slice for teams.
+func (o *teamBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for teams since they don't have any entitlements.
+func (o *teamBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func teamResource(team client.Team) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"team_id": team.TeamId,
+		"name":    team.Name,
+		"created": team.Created.String(),
+	}
+
+	return resource.NewGroupResource(
+		team.Name,
+		teamResourceType,
+		team.TeamId,
+		[]resource.GroupTraitOption{
+			resource.WithGroupProfile(profile),
+		},
+	)
+}
+
+func newTeamBuilder(client *client.LucidchartClient) *teamBuilder {
+	return &teamBuilder{
+		client: client,
+	}
+}
@@ -0,0 +1,166 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+)
+
+// TrashedContentPolicy selects how trashed folders and documents are synced.
+type TrashedContentPolicy string
+
+const (
+	// TrashedContentInclude syncs trashed content like any other, without checking whether it is trashed.
+	TrashedContentInclude TrashedContentPolicy = "include"
+	// TrashedContentExclude skips trashed content, and everything inside of trashed folders.
+	TrashedContentExclude TrashedContentPolicy = "exclude"
+	// TrashedContentMark syncs trashed content with when it was trashed in the description of its resource,
+	// see trashDescription.
+	TrashedContentMark TrashedContentPolicy = "mark"
+)
+
+// trashedAtFunc returns when a folder or document was trashed, or nil when it isn't trashed.
+type trashedAtFunc func(ctx context.Context, id string) (*time.Time, error)
+
+// applyTrashedPolicy drops the trashed resources, or marks every resource with its trash state. Lucid doesn't
+// tell whether content is trashed when listing a folder, so it costs a request per resource unless trashed
+// content is included as is.
+func applyTrashedPolicy(
+	ctx context.Context,
+	policy TrashedContentPolicy,
+	resources []*v2.Resource,
+	trashedAt trashedAtFunc,
+) ([]*v2.Resource, error) {
+	if policy != TrashedContentExclude && policy != TrashedContentMark {
+		return resources, nil
+	}
+
+	var rv []*v2.Resource
+	for _, resource := range resources {
+		trashed, err := trashedAt(ctx, resource.Id.Resource)
+		if err != nil {
+			return nil, err
+		}
+
+		if trashed != nil && policy == TrashedContentExclude {
+			continue
+		}
+
+		if policy == TrashedContentMark && trashed != nil {
+			resource.Description = appendDescription(resource.Description, trashDescription(*trashed))
+		}
+
+		rv = append(rv, resource)
+	}
+
+	return rv, nil
+}
+
+// descriptionSeparator separates the parts of the description of a folder or document.
+const descriptionSeparator = ", "
+
+// trashDescription is the part of the description of a marked folder or document telling when it was
+// trashed, in RFC 3339.
+func trashDescription(trashed time.Time) string {
+	return "trashed at " + trashed.UTC().Format(time.RFC3339)
+}
+
+// appendDescription appends a part to the description of a folder or document.
+func appendDescription(description, part string) string {
+	if description == "" {
+		return part
+	}
+
+	return description + descriptionSeparator + part
+}
+
+// parseRestoreSetting returns the restore setting, which restores trashed content created again with its id.
+func parseRestoreSetting(settings map[string]interface{}) (bool, error) {
+	value, ok := settings["restore"]
+	if !ok {
+		return false, nil
+	}
+	delete(settings, "restore")
+
+	restore, ok := value.(bool)
+	if !ok {
+		return false, invalidSetting("restore", value)
+	}
+
+	return restore, nil
+}
+
+// trashManager implements the trash lifecycle shared by folders and documents. Deleting trashes the
+// content, and deletes it permanently when permanent is set, trashing it first as Lucid only deletes
+// trashed content. Trashed content is only restored by a Create with its id and the restore setting. The
+// trash state is read from Lucid right before each change, as the client doesn't cache its requests.
+type trashManager struct {
+	resourceType *v2.ResourceType
+	trashedAt    trashedAtFunc
+	trash        func(ctx context.Context, id string) error
+	restore      func(ctx context.Context, id string) error
+	delete       func(ctx context.Context, id string) error
+	permanent    bool
+}
+
+func (m *trashManager) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	if resourceId.ResourceType != m.resourceType.Id {
+		return nil, fmt.Errorf("resource type %s is not supported", resourceId.ResourceType)
+	}
+
+	if resourceId.Resource == rootId {
+		return nil, status.Error(codes.InvalidArgument, "baton-lucidchart: the root folder can't be deleted")
+	}
+
+	trashed, err := m.trashedAt(ctx, resourceId.Resource)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if trashed == nil {
+		err = m.trash(ctx, resourceId.Resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !m.permanent {
+		return nil, nil
+	}
+
+	return nil, m.delete(ctx, resourceId.Resource)
+}
+
+// Restore moves trashed content out of the trash when restore is set, and fails otherwise so that trashed
+// content isn't changed without restoring it. Content that isn't trashed is left as is.
+func (m *trashManager) Restore(ctx context.Context, resourceId *v2.ResourceId, restore bool) error {
+	trashed, err := m.trashedAt(ctx, resourceId.Resource)
+	if err != nil {
+		return err
+	}
+
+	if trashed == nil {
+		return nil
+	}
+
+	if !restore {
+		return status.Errorf(
+			codes.FailedPrecondition,
+			"baton-lucidchart: %s %s is trashed, set restore to restore it",
+			m.resourceType.DisplayName,
+			resourceId.Resource,
+		)
+	}
+
+	return m.restore(ctx, resourceId.Resource)
+}
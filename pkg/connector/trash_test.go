@@ -0,0 +1,132 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+	"github.com/conductorone/baton-lucidchart/pkg/lucidtest"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+func TestApplyTrashedPolicy(t *testing.T) {
+	trashedOn := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	trashedAt := func(_ context.Context, id string) (*time.Time, error) {
+		if id == "trashed" {
+			return &trashedOn, nil
+		}
+
+		return nil, nil
+	}
+
+	newResources := func() []*v2.Resource {
+		active, err := documentResource("active", "Active", documentResourceType, nil)
+		require.NoError(t, err)
+
+		trashed, err := documentResource("trashed", "Trashed", documentResourceType, nil)
+		require.NoError(t, err)
+
+		return []*v2.Resource{active, trashed}
+	}
+
+	ctx := context.Background()
+
+	resources, err := applyTrashedPolicy(ctx, TrashedContentInclude, newResources(), trashedAt)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	require.Equal(t, "Trashed", resources[1].DisplayName)
+
+	resources, err = applyTrashedPolicy(ctx, TrashedContentExclude, newResources(), trashedAt)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	require.Equal(t, "active", resources[0].Id.Resource)
+
+	// The names are left as is, the trash state is in the description.
+	resources, err = applyTrashedPolicy(ctx, TrashedContentMark, newResources(), trashedAt)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	require.Equal(t, "Trashed", resources[1].DisplayName)
+	require.Empty(t, resources[0].Description)
+	require.Equal(t, "trashed at 2024-01-01T00:00:00Z", resources[1].Description)
+
+	// Documents have no trait to hold a profile.
+	_, err = rs.GetGroupTrait(resources[1])
+	require.Error(t, err)
+
+	// The type of a marked folder is still known.
+	folder, err := folderResource("300", "Engineering", client.FolderTypeTeam, nil)
+	require.NoError(t, err)
+
+	resources, err = applyTrashedPolicy(ctx, TrashedContentMark, []*v2.Resource{folder}, func(context.Context, string) (*time.Time, error) {
+		return &trashedOn, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Lucid team folder, trashed at 2024-01-01T00:00:00Z", resources[0].Description)
+
+	folderType, ok := folderTypeFromDescription(resources[0].Description)
+	require.True(t, ok)
+	require.Equal(t, client.FolderTypeTeam, folderType)
+}
+
+func TestTrashLifecycle(t *testing.T) {
+	ctx := context.Background()
+	id := &v2.ResourceId{ResourceType: documentResourceType.Id, Resource: "doc"}
+
+	trashed := func(server *lucidtest.Server) bool {
+		var trashed bool
+		server.Inspect(func(fixtures *lucidtest.Fixtures) {
+			document, ok := fixtures.Documents["doc"]
+			require.True(t, ok)
+			trashed = document.Trashed != nil
+		})
+
+		return trashed
+	}
+
+	t.Run("trash and restore", func(t *testing.T) {
+		c, server := newTestConnector(t, testFixtures())
+		documents := newDocumentBuilder(c.client, client.ProductLucidchart, "", c.folderScope, c.trashedPolicy, false, false, nil)
+
+		// Deleting again leaves the document in the trash.
+		for i := 0; i < 2; i++ {
+			_, err := documents.Delete(ctx, id)
+			require.NoError(t, err)
+			require.True(t, trashed(server))
+		}
+		require.Zero(t, server.Count(http.MethodDelete, "/documents/doc"))
+
+		// A trashed document isn't changed without restoring it.
+		_, _, err := documents.Create(ctx, createRequest(t, id, "Architecture", nil, map[string]interface{}{}))
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		_, _, err = documents.Create(ctx, createRequest(t, id, "Architecture", nil, map[string]interface{}{"restore": true}))
+		require.NoError(t, err)
+		require.False(t, trashed(server))
+
+		// Restoring needs the id of the document.
+		_, _, err = documents.Create(ctx, createRequest(t, nil, "Architecture", nil, map[string]interface{}{"restore": true}))
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("permanent delete", func(t *testing.T) {
+		c, server := newTestConnector(t, testFixtures(), WithPermanentDelete(true))
+		documents := newDocumentBuilder(c.client, client.ProductLucidchart, "", c.folderScope, c.trashedPolicy, c.permanentDelete, false, nil)
+
+		_, err := documents.Delete(ctx, id)
+		require.NoError(t, err)
+
+		// Lucid only deletes trashed documents.
+		require.Equal(t, 1, server.Count(http.MethodPost, "/documents/doc/trash"))
+		server.Inspect(func(fixtures *lucidtest.Fixtures) {
+			require.NotContains(t, fixtures.Documents, "doc")
+		})
+	})
+}
@@ -0,0 +1,619 @@
+package lucidtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+)
+
+const rootFolderId = "root"
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /oauth2/token", s.token)
+
+	mux.HandleFunc("GET /users", s.locked(func(w http.ResponseWriter, r *http.Request) {
+		writePage(s, w, r, s.fixtures.Users)
+	}))
+	mux.HandleFunc("GET /teams", s.locked(func(w http.ResponseWriter, r *http.Request) {
+		writePage(s, w, r, s.fixtures.Teams)
+	}))
+	mux.HandleFunc("GET /auditLogs", s.locked(s.listAuditLogs))
+
+	mux.HandleFunc("POST /folders", s.locked(s.createFolder))
+	mux.HandleFunc("GET /folders/{id}", s.locked(s.getFolder))
+	mux.HandleFunc("PATCH /folders/{id}", s.locked(s.updateFolder))
+	mux.HandleFunc("DELETE /folders/{id}", s.locked(s.deleteFolder))
+	mux.HandleFunc("POST /folders/{id}/trash", s.locked(s.trashFolder(true)))
+	mux.HandleFunc("POST /folders/{id}/restore", s.locked(s.trashFolder(false)))
+	mux.HandleFunc("GET /folders/{id}/contents", s.locked(func(w http.ResponseWriter, r *http.Request) {
+		writePage(s, w, r, s.fixtures.FolderContents[r.PathValue("id")])
+	}))
+	mux.HandleFunc("GET /folders/{id}/shares/users", s.locked(func(w http.ResponseWriter, r *http.Request) {
+		writePage(s, w, r, s.fixtures.FolderCollaborators[r.PathValue("id")])
+	}))
+	mux.HandleFunc("PUT /folders/{id}/shares/users/{userId}", s.locked(s.upsertFolderCollaborator))
+	mux.HandleFunc("DELETE /folders/{id}/shares/users/{userId}", s.locked(s.deleteFolderCollaborator))
+	mux.HandleFunc("PUT /folders/{id}/shares/groups/{groupId}", s.locked(s.upsertGroupCollaborator))
+
+	mux.HandleFunc("POST /documents", s.locked(s.createDocument))
+	mux.HandleFunc("GET /documents/{id}", s.locked(s.getDocument))
+	mux.HandleFunc("DELETE /documents/{id}", s.locked(s.deleteDocument))
+	mux.HandleFunc("POST /documents/{id}/trash", s.locked(s.trashDocument(true)))
+	mux.HandleFunc("POST /documents/{id}/restore", s.locked(s.trashDocument(false)))
+	mux.HandleFunc("PUT /documents/{id}/owner", s.locked(s.transferDocumentOwnership))
+	mux.HandleFunc("GET /documents/{id}/shares/users", s.locked(func(w http.ResponseWriter, r *http.Request) {
+		writePage(s, w, r, s.fixtures.DocumentCollaborators[r.PathValue("id")])
+	}))
+	mux.HandleFunc("PUT /documents/{id}/shares/users/{userId}", s.locked(s.upsertDocumentCollaborator))
+	mux.HandleFunc("DELETE /documents/{id}/shares/users/{userId}", s.locked(s.deleteDocumentCollaborator))
+	mux.HandleFunc("PUT /documents/{id}/shares/groups/{groupId}", s.locked(s.upsertGroupCollaborator))
+	mux.HandleFunc("GET /documents/{id}/shares/shareLinks", s.locked(func(w http.ResponseWriter, r *http.Request) {
+		writePage(s, w, r, s.fixtures.ShareLinks[r.PathValue("id")])
+	}))
+	mux.HandleFunc("POST /documents/{id}/shares/shareLinks", s.locked(s.createShareLink))
+	mux.HandleFunc("GET /documents/{id}/shares/shareLinks/{shareLinkId}", s.locked(s.getShareLink))
+	mux.HandleFunc("PATCH /documents/{id}/shares/shareLinks/{shareLinkId}", s.locked(s.updateShareLink))
+	mux.HandleFunc("DELETE /documents/{id}/shares/shareLinks/{shareLinkId}", s.locked(s.deleteShareLink))
+
+	return s.serve(mux)
+}
+
+// locked runs the handler with the mutex held, so it can read and update the fixtures.
+func (s *Server) locked(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		handler(w, r)
+	}
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		GrantType string `json:"grant_type"`
+	}
+	if err := readJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if body.GrantType != "authorization_code" && body.GrantType != "refresh_token" {
+		writeError(w, http.StatusBadRequest, "unsupported grant type")
+		return
+	}
+
+	s.mutex.Lock()
+	accessToken := s.fixtures.AccessToken
+	s.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, client.GetTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: "lucidtest-refresh-token",
+		ExpiresIn:    3600,
+		Expires:      time.Now().Add(time.Hour).UnixMilli(),
+		TokenType:    "Bearer",
+	})
+}
+
+func (s *Server) listAuditLogs(w http.ResponseWriter, r *http.Request) {
+	var events []client.AuditLogEvent
+
+	from, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid from")
+		return
+	}
+
+	for _, event := range s.fixtures.AuditLogs {
+		if !event.Timestamp.Before(from) {
+			events = append(events, event)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	writePage(s, w, r, events)
+}
+
+func (s *Server) createFolder(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name   string `json:"name"`
+		Type   string `json:"type"`
+		Parent *int   `json:"parent"`
+	}
+	if err := readJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	folder := client.Folder{
+		Id:      s.newId(),
+		Type:    body.Type,
+		Name:    body.Name,
+		Created: time.Now().UTC(),
+	}
+	if body.Parent != nil {
+		folder.Parent = *body.Parent
+	}
+
+	if s.fixtures.Folders == nil {
+		s.fixtures.Folders = make(map[string]client.Folder)
+	}
+	s.fixtures.Folders[strconv.Itoa(folder.Id)] = folder
+	s.addContent(folder.Parent, client.FolderContent{Id: folder.Id, Type: folder.Type, Name: folder.Name})
+
+	writeJSON(w, http.StatusCreated, folder)
+}
+
+func (s *Server) getFolder(w http.ResponseWriter, r *http.Request) {
+	folder, ok := s.fixtures.Folders[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, folder)
+}
+
+func (s *Server) updateFolder(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	folder, ok := s.fixtures.Folders[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	// The fields are decoded separately, a null parent moving the folder to the root folder.
+	var body map[string]json.RawMessage
+	if err := readJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if name, ok := body["name"]; ok {
+		if err := json.Unmarshal(name, &folder.Name); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if parent, ok := body["parent"]; ok {
+		var parentId *int
+		if err := json.Unmarshal(parent, &parentId); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		s.removeContent(folder.Parent, id)
+
+		folder.Parent = 0
+		if parentId != nil {
+			folder.Parent = *parentId
+		}
+
+		s.addContent(folder.Parent, client.FolderContent{Id: folder.Id, Type: folder.Type, Name: folder.Name})
+	} else {
+		s.renameContent(folder.Parent, id, folder.Name)
+	}
+
+	s.fixtures.Folders[id] = folder
+
+	writeJSON(w, http.StatusOK, folder)
+}
+
+func (s *Server) deleteFolder(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	folder, ok := s.fixtures.Folders[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "folder not found")
+		return
+	}
+
+	delete(s.fixtures.Folders, id)
+	s.removeContent(folder.Parent, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) trashFolder(trash bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+
+		folder, ok := s.fixtures.Folders[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "folder not found")
+			return
+		}
+
+		folder.Trashed = nil
+		if trash {
+			now := time.Now().UTC()
+			folder.Trashed = &now
+		}
+		s.fixtures.Folders[id] = folder
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) upsertFolderCollaborator(w http.ResponseWriter, r *http.Request) {
+	id, userId, role, ok := collaboratorRequest(w, r)
+	if !ok {
+		return
+	}
+
+	folderId, _ := strconv.Atoi(id)
+	collaborator := client.FolderUserCollaboration{FolderId: folderId, UserId: userId, Role: role, Created: time.Now().UTC()}
+
+	collaborators := s.fixtures.FolderCollaborators[id]
+	updated := false
+	for i := range collaborators {
+		if collaborators[i].UserId == userId {
+			collaborators[i].Role = role
+			collaborator = collaborators[i]
+			updated = true
+		}
+	}
+	if !updated {
+		collaborators = append(collaborators, collaborator)
+	}
+
+	if s.fixtures.FolderCollaborators == nil {
+		s.fixtures.FolderCollaborators = make(map[string][]client.FolderUserCollaboration)
+	}
+	s.fixtures.FolderCollaborators[id] = collaborators
+
+	writeJSON(w, http.StatusOK, collaborator)
+}
+
+func (s *Server) deleteFolderCollaborator(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	collaborators := s.fixtures.FolderCollaborators[id]
+	for i, collaborator := range collaborators {
+		if strconv.Itoa(collaborator.UserId) == r.PathValue("userId") {
+			s.fixtures.FolderCollaborators[id] = append(collaborators[:i:i], collaborators[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "collaborator not found")
+}
+
+func (s *Server) upsertGroupCollaborator(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := readJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	groupId, err := strconv.Atoi(r.PathValue("groupId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid group id")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"groupId": groupId,
+		"role":    body.Role,
+		"created": time.Now().UTC(),
+	})
+}
+
+func (s *Server) createDocument(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Title    string `json:"title"`
+		Product  string `json:"product"`
+		Template string `json:"template"`
+		Parent   *int   `json:"parent"`
+	}
+	if err := readJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	product := body.Product
+	if body.Template != "" {
+		template, ok := s.fixtures.Documents[body.Template]
+		if !ok {
+			writeError(w, http.StatusNotFound, "template not found")
+			return
+		}
+		product = template.Product
+	}
+
+	document := client.Document{
+		DocumentId: strconv.Itoa(s.newId()),
+		Title:      body.Title,
+		Product:    product,
+		Created:    time.Now().UTC(),
+		Owner:      client.DocumentOwner{Id: 1, Type: client.OwnerTypeUser},
+	}
+	if body.Parent != nil {
+		document.Parent = *body.Parent
+	}
+
+	if s.fixtures.Documents == nil {
+		s.fixtures.Documents = make(map[string]client.Document)
+	}
+	s.fixtures.Documents[document.DocumentId] = document
+	s.addContent(document.Parent, client.FolderContent{Id: document.DocumentId, Type: "document", Name: document.Title, Product: product})
+
+	writeJSON(w, http.StatusCreated, document)
+}
+
+func (s *Server) getDocument(w http.ResponseWriter, r *http.Request) {
+	document, ok := s.fixtures.Documents[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "document not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, document)
+}
+
+func (s *Server) deleteDocument(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	document, ok := s.fixtures.Documents[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "document not found")
+		return
+	}
+
+	delete(s.fixtures.Documents, id)
+	s.removeContent(document.Parent, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) trashDocument(trash bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+
+		document, ok := s.fixtures.Documents[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "document not found")
+			return
+		}
+
+		document.Trashed = nil
+		if trash {
+			now := time.Now().UTC()
+			document.Trashed = &now
+		}
+		s.fixtures.Documents[id] = document
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) transferDocumentOwnership(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	document, ok := s.fixtures.Documents[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "document not found")
+		return
+	}
+
+	var body struct {
+		UserId string `json:"userId"`
+	}
+	if err := readJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	document.Owner = client.DocumentOwner{Id: body.UserId, Type: client.OwnerTypeUser}
+	s.fixtures.Documents[id] = document
+
+	writeJSON(w, http.StatusOK, document)
+}
+
+func (s *Server) upsertDocumentCollaborator(w http.ResponseWriter, r *http.Request) {
+	id, userId, role, ok := collaboratorRequest(w, r)
+	if !ok {
+		return
+	}
+
+	collaborator := client.DocumentUserCollaboration{DocumentId: id, UserId: userId, Role: role, Created: time.Now().UTC()}
+
+	collaborators := s.fixtures.DocumentCollaborators[id]
+	updated := false
+	for i := range collaborators {
+		if collaborators[i].UserId == userId {
+			collaborators[i].Role = role
+			collaborator = collaborators[i]
+			updated = true
+		}
+	}
+	if !updated {
+		collaborators = append(collaborators, collaborator)
+	}
+
+	if s.fixtures.DocumentCollaborators == nil {
+		s.fixtures.DocumentCollaborators = make(map[string][]client.DocumentUserCollaboration)
+	}
+	s.fixtures.DocumentCollaborators[id] = collaborators
+
+	writeJSON(w, http.StatusOK, collaborator)
+}
+
+func (s *Server) deleteDocumentCollaborator(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	collaborators := s.fixtures.DocumentCollaborators[id]
+	for i, collaborator := range collaborators {
+		if strconv.Itoa(collaborator.UserId) == r.PathValue("userId") {
+			s.fixtures.DocumentCollaborators[id] = append(collaborators[:i:i], collaborators[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "collaborator not found")
+}
+
+func (s *Server) createShareLink(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var body struct {
+		Role         string                   `json:"role"`
+		LinkSecurity client.ShareLinkSecurity `json:"linkSecurity"`
+	}
+	if err := readJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	shareLinkId := strconv.Itoa(s.newId())
+	shareLink := client.DocumentShareLink{
+		ShareLinkId:  shareLinkId,
+		DocumentId:   id,
+		Role:         body.Role,
+		LinkSecurity: body.LinkSecurity,
+		Created:      now,
+		LastModified: now,
+		AcceptUrl:    s.URL + "/share/" + shareLinkId,
+	}
+
+	if s.fixtures.ShareLinks == nil {
+		s.fixtures.ShareLinks = make(map[string][]client.DocumentShareLink)
+	}
+	s.fixtures.ShareLinks[id] = append(s.fixtures.ShareLinks[id], shareLink)
+
+	writeJSON(w, http.StatusCreated, shareLink)
+}
+
+func (s *Server) getShareLink(w http.ResponseWriter, r *http.Request) {
+	i := s.shareLinkIndex(r)
+	if i == -1 {
+		writeError(w, http.StatusNotFound, "share link not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.fixtures.ShareLinks[r.PathValue("id")][i])
+}
+
+func (s *Server) updateShareLink(w http.ResponseWriter, r *http.Request) {
+	i := s.shareLinkIndex(r)
+	if i == -1 {
+		writeError(w, http.StatusNotFound, "share link not found")
+		return
+	}
+
+	var body struct {
+		Role         string                   `json:"role"`
+		LinkSecurity client.ShareLinkSecurity `json:"linkSecurity"`
+	}
+	if err := readJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	shareLink := &s.fixtures.ShareLinks[r.PathValue("id")][i]
+	if body.Role != "" {
+		shareLink.Role = body.Role
+	}
+	shareLink.LinkSecurity = body.LinkSecurity
+	shareLink.LastModified = time.Now().UTC()
+
+	writeJSON(w, http.StatusOK, shareLink)
+}
+
+func (s *Server) deleteShareLink(w http.ResponseWriter, r *http.Request) {
+	i := s.shareLinkIndex(r)
+	if i == -1 {
+		writeError(w, http.StatusNotFound, "share link not found")
+		return
+	}
+
+	id := r.PathValue("id")
+	shareLinks := s.fixtures.ShareLinks[id]
+	s.fixtures.ShareLinks[id] = append(shareLinks[:i:i], shareLinks[i+1:]...)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) shareLinkIndex(r *http.Request) int {
+	for i, shareLink := range s.fixtures.ShareLinks[r.PathValue("id")] {
+		if shareLink.ShareLinkId == r.PathValue("shareLinkId") {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// collaboratorRequest reads the resource id, the user id and the role of a share upsert.
+func collaboratorRequest(w http.ResponseWriter, r *http.Request) (string, int, string, bool) {
+	userId, err := strconv.Atoi(r.PathValue("userId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid user id")
+		return "", 0, "", false
+	}
+
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := readJSON(r, &body); err != nil || body.Role == "" {
+		writeError(w, http.StatusBadRequest, "invalid role")
+		return "", 0, "", false
+	}
+
+	return r.PathValue("id"), userId, body.Role, true
+}
+
+// contentFolderId returns the id of a parent folder as used in FolderContents.
+func contentFolderId(parent int) string {
+	if parent == 0 {
+		return rootFolderId
+	}
+
+	return strconv.Itoa(parent)
+}
+
+func (s *Server) addContent(parent int, content client.FolderContent) {
+	if s.fixtures.FolderContents == nil {
+		s.fixtures.FolderContents = make(map[string][]client.FolderContent)
+	}
+
+	folderId := contentFolderId(parent)
+	s.fixtures.FolderContents[folderId] = append(s.fixtures.FolderContents[folderId], content)
+}
+
+func (s *Server) removeContent(parent int, id string) {
+	folderId := contentFolderId(parent)
+
+	contents := s.fixtures.FolderContents[folderId]
+	for i := range contents {
+		if contents[i].ID() == id {
+			s.fixtures.FolderContents[folderId] = append(contents[:i:i], contents[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Server) renameContent(parent int, id, name string) {
+	contents := s.fixtures.FolderContents[contentFolderId(parent)]
+	for i := range contents {
+		if contents[i].ID() == id {
+			contents[i].Name = name
+		}
+	}
+}
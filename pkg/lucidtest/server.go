@@ -0,0 +1,285 @@
+// Package lucidtest provides an in-process fake of the Lucid API, to run the connector against in tests.
+package lucidtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"sync"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+)
+
+const (
+	// ApiKey is the API key the server accepts when the fixtures don't set one.
+	ApiKey = "lucidtest-api-key"
+	// AccessToken is the OAuth2 access token the server issues when the fixtures don't set one.
+	AccessToken = "lucidtest-access-token"
+)
+
+// Fixtures are the content of the fake Lucid account. The server updates them as it handles mutations.
+type Fixtures struct {
+	Users []client.User
+	Teams []client.Team
+
+	// Folders and Documents are the details of each folder and document, by id.
+	Folders   map[string]client.Folder
+	Documents map[string]client.Document
+
+	// FolderContents is the content of each folder, by folder id, "root" being the root folder.
+	FolderContents map[string][]client.FolderContent
+
+	// FolderCollaborators and DocumentCollaborators are the user shares of each folder and document, by id.
+	FolderCollaborators   map[string][]client.FolderUserCollaboration
+	DocumentCollaborators map[string][]client.DocumentUserCollaboration
+
+	// ShareLinks are the share links of each document, by document id.
+	ShareLinks map[string][]client.DocumentShareLink
+
+	AuditLogs []client.AuditLogEvent
+
+	// PageSize is the number of items in each page of the lists, everything being in one page when 0. The
+	// pageSize query parameter of the requests takes precedence.
+	PageSize int
+
+	// ApiKey and AccessToken are the credentials the server accepts, ApiKey and AccessToken by default.
+	ApiKey      string
+	AccessToken string
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Failure makes the server answer the matching requests with an error status.
+type Failure struct {
+	// Method is the method of the failing requests, any method when empty.
+	Method string
+	// Path is a path.Match pattern of the failing requests, e.g. "/folders/*/contents".
+	Path string
+	// Status is the status code of the responses, e.g. http.StatusTooManyRequests.
+	Status int
+	// Times is the number of requests that fail, every matching request when 0.
+	Times int
+}
+
+// Server is a fake Lucid API. Its URL is meant to be passed as the base URL of the client.
+type Server struct {
+	*httptest.Server
+
+	mutex    sync.Mutex
+	fixtures Fixtures
+	requests []Request
+	failures []*Failure
+	nextId   int
+}
+
+// NewServer starts a fake Lucid API serving the fixtures. It must be closed once done.
+func NewServer(fixtures Fixtures) *Server {
+	if fixtures.ApiKey == "" {
+		fixtures.ApiKey = ApiKey
+	}
+
+	if fixtures.AccessToken == "" {
+		fixtures.AccessToken = AccessToken
+	}
+
+	s := &Server{
+		fixtures: fixtures,
+		nextId:   1000,
+	}
+
+	s.Server = httptest.NewServer(s.routes())
+
+	return s
+}
+
+// Fail registers a failure, which is checked before handling every request.
+func (s *Server) Fail(failure Failure) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.failures = append(s.failures, &failure)
+}
+
+// Requests returns the requests received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// Count returns the number of requests received with the method, any method when empty, and a path
+// matching the path.Match pattern.
+func (s *Server) Count(method, pattern string) int {
+	count := 0
+	for _, r := range s.Requests() {
+		if matches(method, pattern, r.Method, r.Path) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Inspect calls fn with the current fixtures, for tests to check the outcome of mutations.
+func (s *Server) Inspect(fn func(fixtures *Fixtures)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	fn(&s.fixtures)
+}
+
+// serve wraps the routes to record every request, inject the registered failures and check the credentials.
+func (s *Server) serve(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		s.mutex.Lock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.Query(),
+			Header: r.Header.Clone(),
+			Body:   body,
+		})
+		failure := s.failure(r)
+		apiKey, accessToken := s.fixtures.ApiKey, s.fixtures.AccessToken
+		s.mutex.Unlock()
+
+		if failure != nil {
+			if failure.Status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "1")
+			}
+
+			writeError(w, failure.Status, http.StatusText(failure.Status))
+			return
+		}
+
+		if r.URL.Path != client.TokenPath {
+			authorization := r.Header.Get("Authorization")
+			if authorization != "Bearer "+apiKey && authorization != "Bearer "+accessToken {
+				writeError(w, http.StatusUnauthorized, "invalid credentials")
+				return
+			}
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// failure returns the failure matching the request, consuming one of its times. It must be called with
+// the mutex held.
+func (s *Server) failure(r *http.Request) *Failure {
+	for i, failure := range s.failures {
+		if !matches(failure.Method, failure.Path, r.Method, r.URL.Path) {
+			continue
+		}
+
+		if failure.Times > 0 {
+			failure.Times--
+			if failure.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+
+		return failure
+	}
+
+	return nil
+}
+
+func matches(method, pattern, requestMethod, requestPath string) bool {
+	if method != "" && method != requestMethod {
+		return false
+	}
+
+	ok, _ := path.Match(pattern, requestPath)
+
+	return ok
+}
+
+// newId returns a new numeric id for a created folder, document or share link. It must be called with
+// the mutex held.
+func (s *Server) newId() int {
+	s.nextId++
+
+	return s.nextId
+}
+
+// writePage writes a page of the items, with a Link header to the next page as Lucid does. It must be
+// called with the mutex held.
+func writePage[T any](s *Server, w http.ResponseWriter, r *http.Request, items []T) {
+	start := 0
+	if token := r.URL.Query().Get("pageToken"); token != "" {
+		var err error
+		start, err = strconv.Atoi(token)
+		if err != nil || start < 0 || start > len(items) {
+			writeError(w, http.StatusBadRequest, "invalid page token")
+			return
+		}
+	}
+
+	pageSize := s.fixtures.PageSize
+	if size := r.URL.Query().Get("pageSize"); size != "" {
+		var err error
+		pageSize, err = strconv.Atoi(size)
+		if err != nil || pageSize <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid page size")
+			return
+		}
+	}
+
+	end := len(items)
+	if pageSize > 0 && start+pageSize < end {
+		end = start + pageSize
+
+		next := *r.URL
+		query := next.Query()
+		query.Set("pageToken", strconv.Itoa(end))
+		next.RawQuery = query.Encode()
+
+		w.Header().Set("Link", fmt.Sprintf("<%s%s>; rel=\"next\"", s.URL, next.RequestURI()))
+	}
+
+	page := items[start:end]
+	if page == nil {
+		page = []T{}
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{
+		"code":    strconv.Itoa(status),
+		"message": message,
+	})
+}
+
+func readJSON(r *http.Request, value interface{}) error {
+	return json.NewDecoder(r.Body).Decode(value)
+}
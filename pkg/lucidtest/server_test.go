@@ -0,0 +1,77 @@
+package lucidtest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/conductorone/baton-lucidchart/pkg/connector/client"
+)
+
+func get(t *testing.T, server *Server, path, token string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func TestServerPagination(t *testing.T) {
+	server := NewServer(Fixtures{
+		Users:    []client.User{{UserId: 1}, {UserId: 2}, {UserId: 3}},
+		PageSize: 2,
+	})
+	defer server.Close()
+
+	resp := get(t, server, "/users", ApiKey)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "<"+server.URL+"/users?pageToken=2>; rel=\"next\"", resp.Header.Get("Link"))
+
+	resp = get(t, server, "/users?pageToken=2", ApiKey)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("Link"))
+
+	// The page size of the request takes precedence.
+	resp = get(t, server, "/users?pageSize=1", ApiKey)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "<"+server.URL+"/users?pageSize=1&pageToken=1>; rel=\"next\"", resp.Header.Get("Link"))
+
+	resp = get(t, server, "/users?pageSize=0", ApiKey)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestServerFailuresAndRecording(t *testing.T) {
+	server := NewServer(Fixtures{})
+	defer server.Close()
+
+	server.Fail(Failure{Method: http.MethodGet, Path: "/folders/*/contents", Status: http.StatusTooManyRequests, Times: 1})
+
+	resp := get(t, server, "/folders/1/contents", ApiKey)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	// The failure only happened once.
+	resp = get(t, server, "/folders/1/contents", ApiKey)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = get(t, server, "/folders/1/contents", "wrong")
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = get(t, server, "/folders/1", AccessToken)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	require.Equal(t, 3, server.Count(http.MethodGet, "/folders/*/contents"))
+	requests := server.Requests()
+	require.Len(t, requests, 4)
+	require.True(t, strings.HasSuffix(requests[3].Header.Get("Authorization"), AccessToken))
+}